@@ -0,0 +1,51 @@
+package app
+
+import (
+	"game_hub/core"
+)
+
+type AppCommand struct{ core.BaseCommand }
+
+func (c *AppCommand) Scope() core.Scope {
+	return core.ScopeApp
+}
+
+// HubCommands возвращает команды хаба, доступные в любом состоянии, в том числе внутри игр.
+func HubCommands() []core.Command {
+	return []core.Command{
+		&LanguageCommand{},
+		&AccessibilityCommand{},
+	}
+}
+
+type LanguageCommand struct{ AppCommand }
+
+func (c *LanguageCommand) Id() string {
+	return "language"
+}
+
+func (c *LanguageCommand) Execute(ctx *core.AppContext, ui *core.UiContext, args []string) (core.Transition, error) {
+	if state, err := ctx.GetCurrentState(); err == nil {
+		if _, ok := state.(*LanguageSelectionMenuState); ok {
+			return core.Stay(), nil
+		}
+	}
+	return core.Push(NewLanguageSelectionMenu(ui.LocalizationManager.AvailableLanguages())), nil
+}
+
+// AccessibilityCommand включает и выключает режим доступности для программ экранного доступа.
+type AccessibilityCommand struct{ AppCommand }
+
+func (c *AccessibilityCommand) Id() string {
+	return "accessibility"
+}
+
+func (c *AccessibilityCommand) Execute(ctx *core.AppContext, ui *core.UiContext, args []string) (core.Transition, error) {
+	ui.SetAccessible(!ui.Accessible())
+	if ui.Accessible() {
+		ui.DisplayText(ui.GetLocalizedMsg(ui.AppLocalizer, "accessibility_on") + "\r\n")
+	} else {
+		ui.DisplayText(ui.GetLocalizedMsg(ui.AppLocalizer, "accessibility_off") + "\r\n")
+	}
+	return core.Stay(), nil
+}
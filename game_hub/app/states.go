@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"game_hub/core"
 	"game_hub/utils"
+	"strings"
 )
 
 type BaseAppState struct{ core.BaseState }
@@ -24,11 +25,18 @@ func (s *StartState) Init(ctx *core.AppContext, ui *core.UiContext) (core.State,
 	if err := ui.AppLocalizer.LoadOptionalTranslations(ctx.Config.Paths.GamesTranslationsPath()); err != nil {
 		return &core.ExitState{}, err
 	}
+	if err := ui.CommandRegistry.LoadLocalTranslations(ctx.Config.Paths.AppCommandsPath()); err != nil {
+		return &core.ExitState{}, err
+	}
+	if err := ui.CommandRegistry.RegisterCommands(core.LayerHub, HubCommands()); err != nil {
+		return &core.ExitState{}, err
+	}
 	return s, nil
 }
 
-func (s *StartState) Handle(ctx *core.AppContext, ui *core.UiContext, _ string) (core.State, error) {
-	return NewMainMenu(ctx, ui), nil
+// Handle заменяет стартовое состояние главным меню, которое становится корнем стека.
+func (s *StartState) Handle(ctx *core.AppContext, ui *core.UiContext, _ string) (core.Transition, error) {
+	return core.Replace(NewMainMenu(ctx, ui)), nil
 }
 
 func (s *StartState) RequiresInput() bool {
@@ -46,18 +54,18 @@ func NewMainMenu(ctx *core.AppContext, ui *core.UiContext) *core.MenuState {
 	options := []core.MenuOption{
 		{Id: 0,
 			Description: "exit_option",
-			NextState:   func() core.State { return &core.ExitState{} },
+			Next:        func() core.Transition { return core.Push(&core.ExitState{}) },
 		},
 		{Id: 1,
 			Description: "play_option",
-			NextState: func() core.State {
-				return NewGameSelectionMenu(ctx.AvailableGames)
+			Next: func() core.Transition {
+				return core.Push(NewGameSelectionMenu(ctx.AvailableGames))
 			},
 		},
 		{Id: 2,
 			Description: "change_language_option",
-			NextState: func() core.State {
-				return NewLanguageSelectionMenu(ui.LocalizationManager.AvailableLanguages())
+			Next: func() core.Transition {
+				return core.Push(NewLanguageSelectionMenu(ui.LocalizationManager.AvailableLanguages()))
 			},
 		},
 	}
@@ -81,42 +89,63 @@ func (s *GameSelectionMenuState) Id() string {
 
 func (s *GameSelectionMenuState) Display(ctx *core.AppContext, ui *core.UiContext) {
 	ui.DisplayText(ui.GetLocalizedStateMsg(s, "welcome") + "\r\n")
-	ui.DisplayText(fmt.Sprintf("0. %s\r\n", ui.GetLocalizedStateMsg(s, "exit_option")))
-	ui.DisplayText(ui.GetLocalizedStateMsg(s, "available_games") + "\r\n\r\n")
+	ui.DisplayText(fmt.Sprintf("%s %s\r\n", core.Style(core.StyleOption, "0."), ui.GetLocalizedStateMsg(s, "exit_option")))
+	count := map[string]any{"count": len(s.AvailableGames)}
+	ui.DisplayText(utils.SubstituteParams(ui.GetLocalizedStateMsg(s, "available_games"), count) + "\r\n\r\n")
 	for i, game := range s.AvailableGames {
 		name := ui.GetOptionalLocalizedMsg(ui.AppLocalizer, game.GetId(), "name")
 		desc := ui.GetOptionalLocalizedMsg(ui.AppLocalizer, game.GetId(), "description")
 		author := ui.GetOptionalLocalizedMsg(ui.AppLocalizer, game.GetId(), "author")
-		ui.DisplayText(fmt.Sprintf("%d. %s.\r\n%s\r\n%s: %s.\r\n\r\n", i+1, name, desc, utils.Capitalize(ui.GetLocalizedStateMsg(s, "author")), author))
+		number := core.Style(core.StyleOption, fmt.Sprintf("%d.", i+1))
+		ui.DisplayText(fmt.Sprintf("%s %s.\r\n%s\r\n%s: %s.\r\n\r\n", number, core.Style(core.StyleTitle, name), desc, utils.Capitalize(ui.GetLocalizedStateMsg(s, "author")), author))
 	}
-	ui.DisplayText(ui.GetLocalizedStateMsg(s, "make_your_choice") + "\r\n")
+	ui.DisplayText(utils.SubstituteParams(ui.GetLocalizedStateMsg(s, "make_your_choice"), count) + "\r\n")
 }
 
-func (s *GameSelectionMenuState) Handle(ctx *core.AppContext, ui *core.UiContext, input string) (core.State, error) {
+func (s *GameSelectionMenuState) Handle(ctx *core.AppContext, ui *core.UiContext, input string) (core.Transition, error) {
 	option, err := ui.Validator.ParseInt(input)
 	if err != nil {
-		return s, err
+		return core.Stay(), err
 	}
 	maxOption := len(s.AvailableGames)
 	if option < 0 || option > maxOption {
 		ui.DisplayText(ui.GetLocalizedStateMsg(s, "invalid_option") + "\r\n")
-		return s, nil
+		return core.Stay(), nil
 	}
 	if option == 0 {
-		return NewMainMenu(ctx, ui), nil
+		return core.Pop(), nil
 	}
 	selectedGame := s.AvailableGames[option-1]
-	return &core.InitGameState{Game: selectedGame}, nil
+	s.warnIncompleteTranslation(ui, selectedGame)
+	return core.Push(&core.InitGameState{Game: selectedGame}), nil
+}
+
+func (s *GameSelectionMenuState) warnIncompleteTranslation(ui *core.UiContext, game core.GameInterface) {
+	coverage, err := ui.LocalizationManager.GameCoverage(game.GetId())
+	if err != nil {
+		ui.DisplayError(err)
+		return
+	}
+	lang := ui.LocalizationManager.CurrentLang()
+	if coverage.IsComplete(lang) {
+		return
+	}
+	ui.DisplayText(utils.SubstituteParams(ui.GetLocalizedStateMsg(s, "translation_incomplete"), map[string]any{
+		"percent": coverage.Percent(lang),
+	}) + "\r\n")
 }
 
 type LanguageSelectionMenuState struct {
 	BaseAppState
 	availableLanguages []core.Language
+	coverage           *core.TranslationCoverage
+	gamesCoverage      map[string]*core.TranslationCoverage
 }
 
 func NewLanguageSelectionMenu(langs []core.Language) *LanguageSelectionMenuState {
 	return &LanguageSelectionMenuState{
 		availableLanguages: langs,
+		gamesCoverage:      make(map[string]*core.TranslationCoverage),
 	}
 }
 
@@ -124,27 +153,85 @@ func (m *LanguageSelectionMenuState) Id() string {
 	return "language_selection_menu"
 }
 
+func (m *LanguageSelectionMenuState) Init(ctx *core.AppContext, ui *core.UiContext) (core.State, error) {
+	hubCoverage, err := ui.LocalizationManager.HubCoverage()
+	if err != nil {
+		ui.DisplayError(err)
+		return m, nil
+	}
+	coverage := core.NewTranslationCoverage()
+	coverage.Add(hubCoverage)
+	for _, game := range ctx.AvailableGames {
+		gameCoverage, err := ui.LocalizationManager.GameCoverage(game.GetId())
+		if err != nil {
+			ui.DisplayError(err)
+			return m, nil
+		}
+		m.gamesCoverage[game.GetId()] = gameCoverage
+		coverage.Add(gameCoverage)
+	}
+	m.coverage = coverage
+	return m, nil
+}
+
 func (m *LanguageSelectionMenuState) Display(ctx *core.AppContext, ui *core.UiContext) {
-	ui.DisplayText(ui.GetLocalizedStateMsg(m, "available_languages") + "\r\n\r\n")
-	for _, lang := range m.availableLanguages {
-		ui.DisplayText(fmt.Sprintf("%s: %s.\r\n", lang.Code, lang.Name))
+	count := map[string]any{"count": len(m.availableLanguages)}
+	ui.DisplayText(utils.SubstituteParams(ui.GetLocalizedStateMsg(m, "available_languages"), count) + "\r\n\r\n")
+	for i, lang := range m.availableLanguages {
+		if m.coverage == nil {
+			ui.DisplayText(fmt.Sprintf("%s %s (%s).\r\n", core.Style(core.StyleOption, fmt.Sprintf("%d.", i+1)), lang.Name, lang.Code))
+			continue
+		}
+		ui.DisplayText(utils.SubstituteParams(ui.GetLocalizedStateMsg(m, "language_option"), map[string]any{
+			"number":  i + 1,
+			"name":    lang.Name,
+			"code":    lang.Code,
+			"percent": m.coverage.Percent(lang.Code),
+		}) + "\r\n")
+	}
+	ui.DisplayText("\r\n" + utils.SubstituteParams(ui.GetLocalizedStateMsg(m, "prompt"), count) + "\r\n")
+}
+
+func (s *LanguageSelectionMenuState) Handle(ctx *core.AppContext, ui *core.UiContext, input string) (core.Transition, error) {
+	lang, ok := s.findLanguage(ui, input)
+	if !ok {
+		ui.DisplayText(ui.GetLocalizedStateMsg(s, "invalid_input") + "\r\n")
+		return core.Stay(), nil
 	}
-	ui.DisplayText("\r\n" + ui.GetLocalizedStateMsg(m, "prompt") + "\r\n")
+	previousLang := ui.LocalizationManager.CurrentLang()
+	if err := ui.LocalizationManager.SetCurrentLanguage(lang.Code); err != nil {
+		return core.Stay(), err
+	}
+	ctx.Events.Publish(core.LanguageChanged{From: previousLang, To: lang.Code})
+	ui.CommandRegistry.UpdateAliases()
+	ui.DisplayText(fmt.Sprintf(ui.GetLocalizedStateMsg(s, "selected")+"\r\n", lang.Name))
+	for _, game := range ctx.AvailableGames {
+		gameCoverage, exists := s.gamesCoverage[game.GetId()]
+		if !exists || gameCoverage.IsComplete(lang.Code) {
+			continue
+		}
+		ui.DisplayText(utils.SubstituteParams(ui.GetLocalizedStateMsg(s, "game_incomplete"), map[string]any{
+			"game":    ui.GetOptionalLocalizedMsg(ui.AppLocalizer, game.GetId(), "name"),
+			"percent": gameCoverage.Percent(lang.Code),
+		}) + "\r\n")
+	}
+	return core.Pop(), nil
 }
 
-func (s *LanguageSelectionMenuState) Handle(ctx *core.AppContext, ui *core.UiContext, input string) (core.State, error) {
+// findLanguage ищет язык по его номеру в списке или по коду.
+func (s *LanguageSelectionMenuState) findLanguage(ui *core.UiContext, input string) (core.Language, bool) {
+	if num, err := ui.Validator.ParseInt(input); err == nil {
+		if num < 1 || num > len(s.availableLanguages) {
+			return core.Language{}, false
+		}
+		return s.availableLanguages[num-1], true
+	}
 	for _, lang := range s.availableLanguages {
-		if lang.Code == input {
-			if err := ui.LocalizationManager.SetCurrentLanguage(lang.Code); err != nil {
-				return s, err
-			}
-			ui.CommandRegistry.UpdateAliases()
-			ui.DisplayText(fmt.Sprintf(ui.GetLocalizedStateMsg(s, "selected")+"\r\n", lang.Name))
-			return ctx.GetPreviousState()
+		if strings.EqualFold(lang.Code, input) {
+			return lang, true
 		}
 	}
-	ui.DisplayText(ui.GetLocalizedStateMsg(s, "invalid_input") + "\r\n")
-	return s, nil
+	return core.Language{}, false
 }
 
 func (s *LanguageSelectionMenuState) GetCommands() []core.Command {
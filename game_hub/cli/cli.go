@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"fmt"
+	"game_hub/config"
+	"game_hub/core"
+	"game_hub/utils"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Context содержит всё необходимое для выполнения служебных команд без запуска интерфейса.
+type Context struct {
+	Config              *config.Config
+	Games               []core.GameInterface
+	LocalizationManager *core.LocalizationManager
+	Localizer           *core.MessageLocalizer
+	ErrorHandler        core.ErrorHandler
+}
+
+type handler func(c *Context, args []string) error
+
+var commands = map[string]handler{
+	"data": (*Context).runData,
+	"i18n": (*Context).runI18n,
+}
+
+// IsCommand сообщает, запрошена ли служебная команда вместо интерактивного режима.
+func IsCommand(args []string) bool {
+	return len(args) > 0 && !strings.HasPrefix(args[0], "-")
+}
+
+func Run(cfg *config.Config, games []core.GameInterface, args []string) error {
+	lm, err := core.NewLocalizationManager(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to initialize localization manager: %v\r\n", err)
+		return err
+	}
+	localizer := core.NewMessageLocalizer(lm)
+	errorHandler := core.NewLocalizedErrorHandler(localizer)
+	lm.SetLogger(core.NewStdLogger(os.Stderr, slog.LevelWarn, errorHandler))
+	ctx := &Context{
+		Config:              cfg,
+		Games:               games,
+		LocalizationManager: lm,
+		Localizer:           localizer,
+		ErrorHandler:        errorHandler,
+	}
+	if err := localizer.LoadTranslations(cfg.Paths.CoreTranslationsPath()); err != nil {
+		ctx.PrintError(err)
+		return err
+	}
+	if err := ctx.dispatch(args); err != nil {
+		ctx.PrintError(err)
+		return err
+	}
+	return nil
+}
+
+func (c *Context) dispatch(args []string) error {
+	run, exists := commands[args[0]]
+	if !exists {
+		names := make([]string, 0, len(commands))
+		for name := range commands {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return core.NewAppError(core.ErrInvalidInput, "cli_unknown_command", map[string]any{
+			"command":   args[0],
+			"available": strings.Join(names, ", "),
+		})
+	}
+	return run(c, args[1:])
+}
+
+func (c *Context) Print(key string, params map[string]any) {
+	msg, err := c.Localizer.Get(key)
+	if err != nil {
+		c.PrintError(err)
+		return
+	}
+	fmt.Print(utils.SubstituteParams(msg, params) + "\r\n")
+}
+
+func (c *Context) PrintError(err error) {
+	if msg := c.ErrorHandler.Handle(err); msg != "" {
+		fmt.Fprint(os.Stderr, msg+"\r\n")
+	}
+}
+
+// DataFiles возвращает все существующие файлы данных ядра, приложения и зарегистрированных игр.
+func (c *Context) DataFiles() []string {
+	paths := c.Config.Paths.HubDataPaths()
+	for _, game := range c.Games {
+		paths = append(paths, c.Config.Paths.GameDataPaths(game.GetId())...)
+	}
+	files := make([]string, 0, len(paths))
+	for _, path := range paths {
+		if _, err := os.Stat(path); err == nil {
+			files = append(files, path)
+		}
+	}
+	return files
+}
@@ -0,0 +1,124 @@
+package cli
+
+import (
+	"fmt"
+	"game_hub/core"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func (c *Context) runData(args []string) error {
+	if len(args) == 0 || args[0] != "migrate" {
+		command := "data"
+		if len(args) > 0 {
+			command += " " + args[0]
+		}
+		return core.NewAppError(core.ErrInvalidInput, "cli_unknown_command", map[string]any{
+			"command":   command,
+			"available": "data migrate",
+		})
+	}
+	return c.migrateData(args[1:])
+}
+
+// migrateData переписывает устаревшие файлы данных, настройки и сохранения игр в текущей версии схемы,
+// сохраняя рядом копию оригинала.
+func (c *Context) migrateData(args []string) error {
+	flags := newFlagSet("data migrate")
+	dryRun := flags.Bool("dry-run", false, "")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	files := append([]string{c.Config.Paths.CoreLanguagesPath()}, c.DataFiles()...)
+	if _, err := os.Stat(c.Config.Paths.CoreThemePath()); err == nil {
+		files = append(files, c.Config.Paths.CoreThemePath())
+	}
+	userFiles, err := c.userDataFiles()
+	if err != nil {
+		return err
+	}
+	files = append(files, userFiles...)
+	migrated, current := 0, 0
+	for _, filePath := range files {
+		data, err := core.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+		root, err := core.ParseDataFile(filePath, data)
+		if err != nil {
+			return core.NewAppError(core.ErrLocalization, "file_parse_error", map[string]any{
+				"file":  filePath,
+				"error": err,
+			})
+		}
+		kind := c.Config.Paths.DataFileKind(filePath)
+		from, err := core.MigrateNode(filePath, kind, root)
+		if err != nil {
+			return err
+		}
+		to := core.SchemaVersion(kind)
+		if from == to {
+			current++
+			continue
+		}
+		migrated++
+		relPath := c.relativePath(filePath)
+		if *dryRun {
+			c.Print("data_migration_pending", map[string]any{
+				"file": relPath,
+				"from": from,
+				"to":   to,
+			})
+			continue
+		}
+		backupPath := fmt.Sprintf("%s.v%d.bak", filePath, from)
+		if err := core.WriteFile(backupPath, data); err != nil {
+			return err
+		}
+		encoded, err := core.EncodeDataFile(filePath, root)
+		if err != nil {
+			return err
+		}
+		if err := core.WriteFile(filePath, encoded); err != nil {
+			return err
+		}
+		c.Print("data_migrated", map[string]any{
+			"file":   relPath,
+			"from":   from,
+			"to":     to,
+			"backup": c.relativePath(backupPath),
+		})
+	}
+	summaryKey := "data_migration_summary"
+	if *dryRun {
+		summaryKey = "data_migration_dry_run_summary"
+	}
+	c.Print(summaryKey, map[string]any{
+		"migrated": migrated,
+		"current":  current,
+	})
+	return nil
+}
+
+// userDataFiles возвращает существующие файлы пользователя со схемой: настройки и сохранения игр.
+func (c *Context) userDataFiles() ([]string, error) {
+	files := make([]string, 0)
+	if _, err := os.Stat(c.Config.Paths.SettingsPath()); err == nil {
+		files = append(files, c.Config.Paths.SettingsPath())
+	}
+	saves, err := filepath.Glob(filepath.Join(c.Config.Paths.SavesDir(), "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	return append(files, saves...), nil
+}
+
+// relativePath показывает файлы данных относительно каталога данных, а файлы пользователя, лежащие вне его, — полным путём.
+func (c *Context) relativePath(filePath string) string {
+	relPath, err := c.Config.Paths.RelativePath(filePath)
+	if err != nil || relPath == ".." || strings.HasPrefix(relPath, ".."+string(filepath.Separator)) {
+		return filePath
+	}
+	return filepath.ToSlash(relPath)
+}
@@ -0,0 +1,144 @@
+package cli
+
+import (
+	"flag"
+	"game_hub/core"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func (c *Context) runI18n(args []string) error {
+	if len(args) == 0 {
+		return core.NewAppError(core.ErrInvalidInput, "cli_unknown_command", map[string]any{
+			"command":   "i18n",
+			"available": "i18n export, i18n import",
+		})
+	}
+	switch args[0] {
+	case "export":
+		return c.exportPo(args[1:])
+	case "import":
+		return c.importPo(args[1:])
+	default:
+		return core.NewAppError(core.ErrInvalidInput, "cli_unknown_command", map[string]any{
+			"command":   "i18n " + args[0],
+			"available": "i18n export, i18n import",
+		})
+	}
+}
+
+func newFlagSet(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	return flags
+}
+
+func parseFlags(flags *flag.FlagSet, args []string) error {
+	if err := flags.Parse(args); err != nil {
+		return core.NewAppError(core.ErrInvalidInput, "cli_invalid_arguments", map[string]any{
+			"error": err,
+		})
+	}
+	return nil
+}
+
+// poPath строит путь к файлу gettext, повторяя структуру каталога данных: <dir>/<lang>/core/states.po.
+func poPath(dir, lang, relPath string) string {
+	base := strings.TrimSuffix(relPath, filepath.Ext(relPath))
+	if lang == "" {
+		return filepath.Join(dir, "templates", base+".pot")
+	}
+	return filepath.Join(dir, lang, base+".po")
+}
+
+func (c *Context) exportPo(args []string) error {
+	flags := newFlagSet("i18n export")
+	lang := flags.String("lang", "", "")
+	dir := flags.String("dir", "i18n", "")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	count := 0
+	for _, filePath := range c.DataFiles() {
+		relPath, err := c.Config.Paths.RelativePath(filePath)
+		if err != nil {
+			return core.NewAppError(core.ErrInternal, "file_write_error", map[string]any{
+				"file":  filePath,
+				"error": err,
+			})
+		}
+		po, err := c.LocalizationManager.ExportPo(filePath, filepath.ToSlash(relPath), *lang)
+		if err != nil {
+			return err
+		}
+		if err := core.WriteFile(poPath(*dir, *lang, relPath), po.Encode()); err != nil {
+			return err
+		}
+		count++
+	}
+	c.Print("i18n_exported", map[string]any{
+		"count": count,
+		"dir":   *dir,
+	})
+	return nil
+}
+
+func (c *Context) importPo(args []string) error {
+	flags := newFlagSet("i18n import")
+	lang := flags.String("lang", "", "")
+	dir := flags.String("dir", "i18n", "")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if *lang == "" {
+		return core.NewAppError(core.ErrInvalidInput, "i18n_lang_required", nil)
+	}
+	for _, filePath := range c.DataFiles() {
+		relPath, err := c.Config.Paths.RelativePath(filePath)
+		if err != nil {
+			return core.NewAppError(core.ErrInternal, "file_open_error", map[string]any{
+				"file":  filePath,
+				"error": err,
+			})
+		}
+		sourcePath := poPath(*dir, *lang, relPath)
+		if _, err := os.Stat(sourcePath); os.IsNotExist(err) {
+			continue
+		}
+		data, err := core.ReadFile(sourcePath)
+		if err != nil {
+			return err
+		}
+		po, err := core.ParsePo(data)
+		if err != nil {
+			return core.NewAppError(core.ErrLocalization, "file_parse_error", map[string]any{
+				"file":  sourcePath,
+				"error": err,
+			})
+		}
+		if poLang := po.Header["Language"]; poLang != "" && poLang != *lang {
+			return core.NewAppError(core.ErrLocalization, "i18n_language_mismatch", map[string]any{
+				"file": sourcePath,
+				"lang": poLang,
+			})
+		}
+		result, err := c.LocalizationManager.ImportPo(filePath, po, *lang)
+		if err != nil {
+			return err
+		}
+		c.Print("i18n_imported", map[string]any{
+			"file":    filepath.ToSlash(relPath),
+			"updated": result.Updated,
+			"skipped": result.Skipped,
+		})
+		if !result.Complete {
+			c.Print("i18n_incomplete", map[string]any{
+				"file": filepath.ToSlash(relPath),
+				"lang": *lang,
+			})
+		}
+	}
+	return nil
+}
@@ -1,9 +1,55 @@
 package config
 
+// Front-ends that can be selected with the --ui flag.
+const (
+	// UILine is the default line-based console.
+	UILine = "line"
+	// UITui is the full-screen terminal interface.
+	UITui = "tui"
+)
+
+// Log levels accepted by the --log-level flag and the log_level setting.
+const (
+	LogDebug = "debug"
+	LogInfo  = "info"
+	LogWarn  = "warn"
+	LogError = "error"
+	// LogOff disables the log file.
+	LogOff = "off"
+)
+
+// LogLevels lists the log levels from the most to the least verbose.
+var LogLevels = []string{LogDebug, LogInfo, LogWarn, LogError, LogOff}
+
+// WrapAuto wraps text at the width of the terminal.
+const WrapAuto = -1
+
+// HistoryDefault is the number of lines kept in each input history when neither the --history-size flag
+// nor the history_size setting is given.
+const HistoryDefault = 200
+
+// HistoryUnset means that the history size comes from the history_size setting.
+const HistoryUnset = -1
+
 // Config contains all application configuration settings.
 type Config struct {
 	Paths    *PathConfig
 	Language *LanguageConfig
+	// Debug enables hidden developer commands.
+	Debug bool
+	// UI selects the front-end, UILine or UITui.
+	UI string
+	// WrapWidth is the number of columns text is wrapped at: WrapAuto follows the terminal and 0 disables wrapping.
+	WrapWidth int
+	// Accessible starts the hub in the screen-reader friendly mode: plain unwrapped text,
+	// explicit prompts and line input instead of single keys and the full-screen interface.
+	Accessible bool
+	// LogLevel is the least severe level written to the log file. Empty means the log_level setting,
+	// or LogDebug in debug mode and LogInfo otherwise.
+	LogLevel string
+	// HistorySize is the number of lines kept in each input history; 0 disables the history.
+	// HistoryUnset means the history_size setting, or HistoryDefault.
+	HistorySize int
 }
 
 // NewConfig creates a new Config instance with initialized PathConfig and LanguageConfig.
@@ -14,7 +60,10 @@ func NewConfig(appName string) (*Config, error) {
 	}
 	languageConfig := NewLanguageConfig()
 	return &Config{
-		Paths:    pathConfig,
-		Language: languageConfig,
+		Paths:       pathConfig,
+		Language:    languageConfig,
+		UI:          UILine,
+		WrapWidth:   WrapAuto,
+		HistorySize: HistoryUnset,
 	}, nil
 }
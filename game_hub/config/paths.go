@@ -6,13 +6,21 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"sync"
 )
 
 // PathConfig manages paths to configuration files.
 type PathConfig struct {
 	baseDir    string
 	gamesDir   string
+	userDir    string
+	stateDir   string
 	isPortable bool
+
+	// dataFiles caches, per directory, the file chosen for each data file name,
+	// so that the format is resolved with a single directory read.
+	dataFilesMu sync.Mutex
+	dataFiles   map[string]map[string]string
 }
 
 // NewPathConfig creates a new PathConfig instance and validates the configuration directory.
@@ -33,6 +41,8 @@ func NewPathConfig(appName string) (*PathConfig, error) {
 		return &PathConfig{
 			baseDir:    dataDir,
 			gamesDir:   gamesDir,
+			userDir:    filepath.Join(exeDir, "user"),
+			stateDir:   filepath.Join(exeDir, "user"),
 			isPortable: true,
 		}, nil
 	}
@@ -67,34 +77,69 @@ func NewPathConfig(appName string) (*PathConfig, error) {
 		return nil, err
 	}
 
+	user := userDir(appName, baseDir)
 	return &PathConfig{
 		baseDir:    baseDir,
 		gamesDir:   gamesDir,
+		userDir:    user,
+		stateDir:   stateDir(runtime.GOOS, appName, user),
 		isPortable: false,
 	}, nil
 }
 
+// userDir returns the writable directory for settings and saves of an installed application.
+// The shared configuration directory is usually read-only, so the per-user one is preferred.
+func userDir(appName, baseDir string) string {
+	if dir, err := os.UserConfigDir(); err == nil {
+		return filepath.Join(dir, appName)
+	}
+	return filepath.Join(baseDir, "user")
+}
+
+// stateDir returns the per-user directory for logs and other files the user does not edit:
+// XDG_STATE_HOME or ~/.local/state on Linux, ~/Library/Logs on macOS and %LocalAppData% on Windows.
+// When none of them is available, the user directory is used.
+func stateDir(platform, appName, userDir string) string {
+	home, homeErr := os.UserHomeDir()
+	switch {
+	case platform == "linux" && os.Getenv("XDG_STATE_HOME") != "":
+		return filepath.Join(os.Getenv("XDG_STATE_HOME"), appName)
+	case platform == "linux" && homeErr == nil:
+		return filepath.Join(home, ".local", "state", appName)
+	case platform == "darwin" && homeErr == nil:
+		return filepath.Join(home, "Library", "Logs", appName)
+	case platform == "windows" && os.Getenv("LocalAppData") != "":
+		return filepath.Join(os.Getenv("LocalAppData"), appName)
+	}
+	return userDir
+}
+
 func (pc *PathConfig) CoreTranslationsPath() string {
-	return filepath.Join(pc.baseDir, "core", "translations.json")
+	return pc.dataFile(filepath.Join(pc.baseDir, "core"), "translations")
 }
 
 // CoreStatesPath returns the path to states.json in core.
 func (pc *PathConfig) CoreStatesPath() string {
-	return filepath.Join(pc.baseDir, "core", "states.json")
+	return pc.dataFile(filepath.Join(pc.baseDir, "core"), "states")
 }
 
 // CoreGlobalCommandsPath returns the path to global_commands.json in core.
 func (pc *PathConfig) CoreGlobalCommandsPath() string {
-	return filepath.Join(pc.baseDir, "core", "global_commands.json")
+	return pc.dataFile(filepath.Join(pc.baseDir, "core"), "global_commands")
 }
 
 // CoreLocalCommandsPath returns the path to local_commands.json in core.
 func (pc *PathConfig) CoreLocalCommandsPath() string {
-	return filepath.Join(pc.baseDir, "core", "local_commands.json")
+	return pc.dataFile(filepath.Join(pc.baseDir, "core"), "local_commands")
 }
 
 func (pc *PathConfig) CoreLanguagesPath() string {
-	return filepath.Join(pc.baseDir, "core", "languages.json")
+	return pc.dataFile(filepath.Join(pc.baseDir, "core"), "languages")
+}
+
+// CoreThemePath returns the path to theme.json in core, which maps markup styles to terminal colors.
+func (pc *PathConfig) CoreThemePath() string {
+	return pc.dataFile(filepath.Join(pc.baseDir, "core"), "theme")
 }
 
 func (pc *PathConfig) IsCorePath(filePath string) bool {
@@ -103,32 +148,185 @@ func (pc *PathConfig) IsCorePath(filePath string) bool {
 
 // AppTranslationsPath returns the path to translations.json in app.
 func (pc *PathConfig) AppTranslationsPath() string {
-	return filepath.Join(pc.baseDir, "app", "translations.json")
+	return pc.dataFile(filepath.Join(pc.baseDir, "app"), "translations")
 }
 
 // AppStatesPath returns the path to states.json in app.
 func (pc *PathConfig) AppStatesPath() string {
-	return filepath.Join(pc.baseDir, "app", "states.json")
+	return pc.dataFile(filepath.Join(pc.baseDir, "app"), "states")
+}
+
+// AppCommandsPath returns the path to commands.json in app.
+func (pc *PathConfig) AppCommandsPath() string {
+	return pc.dataFile(filepath.Join(pc.baseDir, "app"), "commands")
 }
 
 // GamesTranslationsPath returns the path to translations.json for games.
 func (pc *PathConfig) GamesTranslationsPath() string {
-	return filepath.Join(pc.gamesDir, "translations.json")
+	return pc.dataFile(pc.gamesDir, "translations")
 }
 
 // GameStatesPath returns the path to states.json for a specific game.
 func (pc *PathConfig) GameStatesPath(gameID string) string {
-	return filepath.Join(pc.gamesDir, gameID, "states.json")
+	return pc.dataFile(filepath.Join(pc.gamesDir, gameID), "states")
 }
 
 // GameCommandsPath returns the path to commands.json for a specific game.
 func (pc *PathConfig) GameCommandsPath(gameID string) string {
-	return filepath.Join(pc.gamesDir, gameID, "commands.json")
+	return pc.dataFile(filepath.Join(pc.gamesDir, gameID), "commands")
 }
 
 // GameTranslationsPath returns the path to translations.json for a specific game.
 func (pc *PathConfig) GameTranslationsPath(gameID string) string {
-	return filepath.Join(pc.gamesDir, gameID, "translations.json")
+	return pc.dataFile(filepath.Join(pc.gamesDir, gameID), "translations")
+}
+
+// SettingsPath returns the path to the user settings file.
+func (pc *PathConfig) SettingsPath() string {
+	return filepath.Join(pc.userDir, "settings.json")
+}
+
+// SavesDir returns the directory holding the autosave files of all games.
+func (pc *PathConfig) SavesDir() string {
+	return filepath.Join(pc.userDir, "saves")
+}
+
+// GameSavePath returns the path to the autosave file of a specific game.
+func (pc *PathConfig) GameSavePath(gameID string) string {
+	return filepath.Join(pc.SavesDir(), gameID+".json")
+}
+
+// CrashReportPath returns the path to a crash report with the given name.
+func (pc *PathConfig) CrashReportPath(name string) string {
+	return filepath.Join(pc.userDir, "crashes", name)
+}
+
+// LogPath returns the path to the log file; older logs are kept next to it with numeric suffixes.
+func (pc *PathConfig) LogPath() string {
+	return filepath.Join(pc.stateDir, "logs", "game_hub.log")
+}
+
+// HistoryPath returns the path to the input history of a scope such as "hub", "commands" or a game.
+func (pc *PathConfig) HistoryPath(scope string) string {
+	return filepath.Join(pc.stateDir, "history", scope+".history")
+}
+
+// RelativePath returns the path of a data file relative to the data directory.
+func (pc *PathConfig) RelativePath(filePath string) (string, error) {
+	return filepath.Rel(pc.baseDir, filePath)
+}
+
+// HubDataPaths returns the paths to all localization files shared by the hub.
+func (pc *PathConfig) HubDataPaths() []string {
+	return []string{
+		pc.CoreTranslationsPath(),
+		pc.CoreStatesPath(),
+		pc.CoreGlobalCommandsPath(),
+		pc.CoreLocalCommandsPath(),
+		pc.AppTranslationsPath(),
+		pc.AppStatesPath(),
+		pc.AppCommandsPath(),
+		pc.GamesTranslationsPath(),
+	}
+}
+
+// GameDataPaths returns the paths to all localization files of a specific game.
+func (pc *PathConfig) GameDataPaths(gameID string) []string {
+	return []string{
+		pc.GameStatesPath(gameID),
+		pc.GameCommandsPath(gameID),
+		pc.GameTranslationsPath(gameID),
+	}
+}
+
+// DataFileKind identifies the schema a data file follows.
+type DataFileKind string
+
+const (
+	MessagesFile    DataFileKind = "messages"
+	MessageSetsFile DataFileKind = "message_sets"
+	StatesFile      DataFileKind = "states"
+	CommandsFile    DataFileKind = "commands"
+	LanguagesFile   DataFileKind = "languages"
+	SettingsFile    DataFileKind = "settings"
+	GameSaveFile    DataFileKind = "game_save"
+	ThemeFile       DataFileKind = "theme"
+)
+
+// DataFileKind returns the schema of a data file judging by its location and name.
+func (pc *PathConfig) DataFileKind(filePath string) DataFileKind {
+	if filePath == pc.GamesTranslationsPath() {
+		return MessageSetsFile
+	}
+	if filePath == pc.SettingsPath() {
+		return SettingsFile
+	}
+	if strings.HasPrefix(filePath, pc.SavesDir()) {
+		return GameSaveFile
+	}
+	name := filepath.Base(filePath)
+	switch strings.TrimSuffix(name, filepath.Ext(name)) {
+	case "states":
+		return StatesFile
+	case "commands", "global_commands", "local_commands":
+		return CommandsFile
+	case "languages":
+		return LanguagesFile
+	case "theme":
+		return ThemeFile
+	default:
+		return MessagesFile
+	}
+}
+
+// dataExtensions lists the supported data file formats in order of precedence.
+var dataExtensions = []string{".json", ".yaml", ".yml", ".toml"}
+
+// dataFile returns the path to a data file in whichever supported format exists,
+// falling back to JSON when none is found.
+func (pc *PathConfig) dataFile(dir, name string) string {
+	pc.dataFilesMu.Lock()
+	defer pc.dataFilesMu.Unlock()
+	files, scanned := pc.dataFiles[dir]
+	if !scanned {
+		files = scanDataFiles(dir)
+		if pc.dataFiles == nil {
+			pc.dataFiles = make(map[string]map[string]string)
+		}
+		pc.dataFiles[dir] = files
+	}
+	if file, exists := files[name]; exists {
+		return filepath.Join(dir, file)
+	}
+	return filepath.Join(dir, name+".json")
+}
+
+// scanDataFiles maps data file names in a directory to the file in the format of highest precedence.
+func scanDataFiles(dir string) map[string]string {
+	files := make(map[string]string)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return files
+	}
+	present := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			present[entry.Name()] = true
+		}
+	}
+	for fileName := range present {
+		name := strings.TrimSuffix(fileName, filepath.Ext(fileName))
+		if _, resolved := files[name]; resolved {
+			continue
+		}
+		for _, candidate := range dataExtensions {
+			if present[name+candidate] {
+				files[name] = name + candidate
+				break
+			}
+		}
+	}
+	return files
 }
 
 func OsConfigDir(platform string) (string, error) {
@@ -9,8 +9,8 @@ type AppContext struct {
 	Game           GameInterface
 	AvailableGames []GameInterface
 	StateStack     *StateStack
-	AppIsRunning   bool
-	GoToMenu       bool
+	Events         *EventBus
+	Scheduler      *Scheduler
 }
 
 func (app *AppContext) GetCurrentState() (State, error) {
@@ -20,28 +20,129 @@ func (app *AppContext) GetCurrentState() (State, error) {
 	return app.StateStack.Peek(), nil
 }
 
-func (app *AppContext) GetPreviousState() (State, error) {
-	if app.StateStack.IsEmpty() {
-		return nil, NewAppError(ErrStateStack, "state_stack_empty", nil)
+func (app *AppContext) GoToState(nextState State, ui *UiContext) (State, error) {
+	return app.Apply(Push(nextState), ui)
+}
+
+// LeaveGame сохраняет текущую игру и выгружает её переводы и команды; повторный вызов ничего не делает.
+func (app *AppContext) LeaveGame(ui *UiContext) error {
+	if app.Game == nil {
+		return nil
 	}
-	if len(app.StateStack.states) < 2 {
-		return nil, NewAppError(ErrStateStack, "state_stack_insufficient", nil)
+	err := SaveGame(app)
+	app.detachGame(ui)
+	return err
+}
+
+// detachGame выгружает переводы и команды игры, не сохраняя её.
+func (app *AppContext) detachGame(ui *UiContext) {
+	ui.GameLocalizer = NewMessageLocalizer(ui.LocalizationManager)
+	ui.CommandRegistry.ClearCommands(LayerGame)
+	app.Game = nil
+}
+
+// Apply применяет переход к стеку состояний и возвращает состояние, ставшее текущим.
+// По пути вызываются хуки жизненного цикла затронутых состояний.
+func (app *AppContext) Apply(transition Transition, ui *UiContext) (State, error) {
+	switch transition.Kind {
+	case TransitionPush:
+		pauseErr := app.pause(ui)
+		state, err := app.push(transition.State, ui)
+		return state, JoinErrors(pauseErr, err)
+	case TransitionReplace:
+		exitErr := app.exitTo(app.StateStack.Len()-1, ui)
+		state, err := app.push(transition.State, ui)
+		return state, JoinErrors(exitErr, err)
+	case TransitionPop:
+		if app.StateStack.Len() < 2 {
+			state, _ := app.GetCurrentState()
+			return state, NewAppError(ErrInvalidInput, "nothing_to_go_back_to", nil)
+		}
+		exitErr := app.exitTo(app.StateStack.Len()-1, ui)
+		state, err := app.resume(ui)
+		return state, JoinErrors(exitErr, err)
+	case TransitionPopToRoot:
+		exitErr := app.exitTo(1, ui)
+		state, err := app.resume(ui)
+		return state, JoinErrors(exitErr, err)
+	case TransitionResetToGameStart:
+		if app.Game == nil {
+			return app.GetCurrentState()
+		}
+		// состояние инициализации игры остаётся в стеке, поэтому новые данные игры не создаются
+		index := app.StateStack.LastIndex(isGameInit)
+		if index < 0 {
+			index = 0
+		}
+		// состояние инициализации сразу же перекрывается снова, поэтому оно не возобновляется
+		exitErr := app.exitTo(index+1, ui)
+		state, err := app.push(app.Game.GetStartState(), ui)
+		return state, JoinErrors(exitErr, err)
+	default:
+		return app.GetCurrentState()
 	}
-	app.StateStack.Pop()
-	return app.StateStack.Pop(), nil
 }
 
-func (app *AppContext) GoToState(nextState State, ui *UiContext) (State, error) {
-	app.StateStack.Push(nextState)
-	if newState, err := nextState.Init(app, ui); err != nil {
-		if newState != nextState {
+// Unwind убирает из стека все состояния, вызывая их OnExit, например перед завершением программы.
+func (app *AppContext) Unwind(ui *UiContext) error {
+	return app.exitTo(0, ui)
+}
+
+func (app *AppContext) push(state State, ui *UiContext) (State, error) {
+	var exitErr error
+	if hook, ok := app.StateStack.Push(state).(ExitHook); ok {
+		exitErr = hook.OnExit(app, ui)
+	}
+	newState, err := app.enter(state, ui)
+	return newState, JoinErrors(exitErr, err)
+}
+
+func (app *AppContext) enter(state State, ui *UiContext) (State, error) {
+	if newState, err := state.Init(app, ui); err != nil {
+		if newState != state {
 			app.StateStack.Pop()
 			app.StateStack.Push(newState)
 		}
 		return newState, err
 	}
-	if err := ui.CommandRegistry.RegisterLocalCommands(nextState.GetCommands()); err != nil {
-		return nextState, err
+	if err := ui.CommandRegistry.RegisterLocalCommands(state.GetCommands()); err != nil {
+		return state, err
+	}
+	app.Events.Publish(StateEntered{State: state})
+	if hook, ok := state.(EnterHook); ok {
+		return state, hook.OnEnter(app, ui)
+	}
+	return state, nil
+}
+
+func (app *AppContext) resume(ui *UiContext) (State, error) {
+	state, err := app.GetCurrentState()
+	if err != nil {
+		return nil, err
+	}
+	if err := ui.CommandRegistry.RegisterLocalCommands(state.GetCommands()); err != nil {
+		return state, err
+	}
+	if hook, ok := state.(ResumeHook); ok {
+		return state, hook.OnResume(app, ui)
+	}
+	return state, nil
+}
+
+func (app *AppContext) pause(ui *UiContext) error {
+	if hook, ok := app.StateStack.Peek().(PauseHook); ok {
+		return hook.OnPause(app, ui)
+	}
+	return nil
+}
+
+// exitTo снимает состояния с вершины, пока в стеке не останется size, вызывая OnExit у каждого снятого.
+func (app *AppContext) exitTo(size int, ui *UiContext) error {
+	errs := make([]error, 0)
+	for app.StateStack.Len() > size {
+		if hook, ok := app.StateStack.Pop().(ExitHook); ok {
+			errs = append(errs, hook.OnExit(app, ui))
+		}
 	}
-	return nextState, nil
+	return JoinErrors(errs...)
 }
@@ -1,16 +1,26 @@
 package core
 
 type Command interface {
-	Execute(ctx *AppContext, ui *UiContext, args []string) (State, error)
+	Execute(ctx *AppContext, ui *UiContext, args []string) (Transition, error)
 	Id() string
 	Scope() Scope
 }
 
+// ConditionalCommand реализуют команды, доступные не всегда: недоступная команда не распознаётся и не показывается в справке.
+type ConditionalCommand interface {
+	IsAvailable(ctx *AppContext) bool
+}
+
+// HiddenCommand реализуют команды для разработчиков: они доступны только в режиме отладки.
+type HiddenCommand interface {
+	IsHidden() bool
+}
+
 type BaseCommand struct{}
 
-func (c *BaseCommand) Execute(ctx *AppContext, ui *UiContext, args []string) (State, error) {
+func (c *BaseCommand) Execute(ctx *AppContext, ui *UiContext, args []string) (Transition, error) {
 	ui.DisplayText(ui.GetLocalizedMsg(ui.AppLocalizer, "unknown_command_action"))
-	return ctx.GetCurrentState()
+	return Stay(), nil
 }
 
 func (c *BaseCommand) Id() string {
@@ -21,6 +31,13 @@ func (c *BaseCommand) Scope() Scope {
 	return ScopeCore
 }
 
+// DevCommand — основа для скрытых команд разработчика.
+type DevCommand struct{ BaseCommand }
+
+func (c *DevCommand) IsHidden() bool {
+	return true
+}
+
 type GameCommand struct{ BaseCommand }
 
 func (c *GameCommand) Scope() Scope {
@@ -32,5 +49,7 @@ func DefaultGlobalCommands() []Command {
 		&HelpCommand{},
 		&QuitCommand{},
 		&VersionCommand{},
+		&WhereCommand{},
+		&CrashCommand{},
 	}
 }
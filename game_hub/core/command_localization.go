@@ -10,38 +10,28 @@ type CommandTranslation struct {
 	Aliases     map[string][]string `json:"aliases"`
 }
 
-func (c CommandTranslation) isLocalized(langs []string) error {
+func (c CommandTranslation) checkLocalized(diagnostics *DataDiagnostics, path []string, langs []string) {
 	for _, supportedLang := range langs {
-		if _, exists := c.Name[supportedLang]; exists {
-			continue
+		if _, exists := c.Name[supportedLang]; !exists {
+			diagnostics.Add(childPath(path, "name", supportedLang), missingTranslation(supportedLang))
 		}
-		return NewAppError(Err, "key_not_found", map[string]any{
-			"key": fmt.Sprintf("Name.%s", supportedLang),
-		})
 	}
 	for _, supportedLang := range langs {
 		if len(c.Description) == 0 {
 			break
 		}
-		if _, exists := c.Description[supportedLang]; exists {
-			continue
+		if _, exists := c.Description[supportedLang]; !exists {
+			diagnostics.Add(childPath(path, "description", supportedLang), missingTranslation(supportedLang))
 		}
-		return NewAppError(Err, "key_not_found", map[string]any{
-			"key": fmt.Sprintf("description.%s", supportedLang),
-		})
 	}
 	for _, supportedLang := range langs {
 		if len(c.Aliases) == 0 {
 			break
 		}
-		if _, exists := c.Aliases[supportedLang]; exists {
-			continue
+		if _, exists := c.Aliases[supportedLang]; !exists {
+			diagnostics.Add(childPath(path, "aliases", supportedLang), missingTranslation(supportedLang))
 		}
-		return NewAppError(Err, "key_not_found", map[string]any{
-			"key": fmt.Sprintf("aliases.%s", supportedLang),
-		})
 	}
-	return nil
 }
 
 func NewCommandTranslation() CommandTranslation {
@@ -55,8 +45,8 @@ func NewCommandTranslation() CommandTranslation {
 type CommandTranslations map[Scope]map[string]CommandTranslation
 
 type CommandLocalizationData struct {
-	Meta         LocalizationMetadata `json:"meta"`
-	Translations CommandTranslations  `json:"translations"`
+	Meta         LocalizationMetadata `json:"meta" validate:"required"`
+	Translations CommandTranslations  `json:"translations" validate:"required"`
 }
 
 type CommandLocalizer struct {
@@ -73,29 +63,54 @@ func NewCommandLocalizer(lm *LocalizationManager) *CommandLocalizer {
 
 func (l *CommandLocalizer) LoadTranslations(filePath string) error {
 	var rawData CommandLocalizationData
-	if err := l.lm.loadLocalizationData(filePath, &rawData); err != nil {
+	node, err := l.lm.loadLocalizationData(filePath, &rawData)
+	if err != nil {
 		return err
 	}
 	supportedLanguages := rawData.Meta.SupportedLanguages
+	invalid := NewDataDiagnostics(filePath, node)
+	unnamed := NewDataDiagnostics(filePath, node)
+	diagnostics := NewDataDiagnostics(filePath, node)
 	for scope, cmds := range rawData.Translations {
 		if !scope.IsValid() {
-			return NewAppError(ErrLocalization, "invalid_command_localizations_scope", map[string]any{
-				"file":  filePath,
-				"scope": scope,
-			})
+			invalid.Add([]string{"translations", string(scope)}, invalidScope(scope))
+			continue
 		}
+		for cmdId, trans := range cmds {
+			path := []string{"translations", string(scope), cmdId}
+			if len(trans.Name) == 0 {
+				unnamed.Add(childPath(path, "name"), NewAppError(Err, "validation_required", nil))
+				continue
+			}
+			trans.checkLocalized(diagnostics, path, supportedLanguages)
+		}
+	}
+	if err := invalid.Err(); err != nil {
+		return NewAppError(ErrLocalization, "localization_file_error", map[string]any{
+			"file":  filePath,
+			"error": err,
+		})
+	}
+	// команды без названия пропускаются, но загрузка файла продолжается
+	if err := unnamed.Err(); err != nil {
+		l.lm.logError(NewAppError(ErrLocalization, "localization_file_error", map[string]any{
+			"file":  filePath,
+			"error": err,
+		}))
+	}
+	if err := diagnostics.Err(); err != nil {
+		locErr := missingTranslationsError(err)
+		if l.lm.isCoreLocalization(filePath) {
+			return locErr
+		}
+		l.lm.logError(locErr)
+	}
+	for scope, cmds := range rawData.Translations {
 		if _, exists := l.Translations[scope]; !exists {
 			l.Translations[scope] = make(map[string]CommandTranslation)
 		}
 		for cmdId, trans := range cmds {
 			if len(trans.Name) == 0 {
-				l.lm.logError(NewAppError(ErrLocalization, "localization_file_translations_error", map[string]any{
-					"file": filePath,
-					"path": fmt.Sprintf("translations.%v.%s", scope, cmdId),
-					"error": NewAppError(Err, "key_not_found", map[string]any{
-						"key": fmt.Sprintf("%s.name", cmdId),
-					}),
-				}))
 				continue
 			}
 			if trans.Description == nil {
@@ -104,18 +119,6 @@ func (l *CommandLocalizer) LoadTranslations(filePath string) error {
 			if trans.Aliases == nil {
 				trans.Aliases = make(map[string][]string)
 			}
-			err := trans.isLocalized(supportedLanguages)
-			if err != nil {
-				locErr := NewAppError(ErrLocalization, "localization_file_translations_error", map[string]any{
-					"file":  filePath,
-					"path":  fmt.Sprintf("translations.%v.%s", scope, cmdId),
-					"error": err,
-				})
-				if l.lm.isCoreLocalization(filePath) {
-					return locErr
-				}
-				l.lm.logError(locErr)
-			}
 			l.Translations[scope][cmdId] = trans
 		}
 	}
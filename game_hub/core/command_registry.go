@@ -5,19 +5,66 @@ import (
 	"strings"
 )
 
+// CommandLayer определяет, где доступна команда. При совпадении названий побеждает слой с меньшим значением.
+type CommandLayer int
+
+const (
+	// команды текущего состояния
+	LayerState CommandLayer = iota
+	// команды запущенной игры, доступные во всех её состояниях
+	LayerGame
+	// команды хаба, доступные везде, в том числе внутри игр
+	LayerHub
+	// встроенные команды ядра
+	LayerGlobal
+)
+
+// CommandLayers перечисляет слои в порядке убывания приоритета.
+var CommandLayers = []CommandLayer{LayerState, LayerGame, LayerHub, LayerGlobal}
+
+func (l CommandLayer) String() string {
+	switch l {
+	case LayerState:
+		return "state"
+	case LayerGame:
+		return "game"
+	case LayerHub:
+		return "hub"
+	case LayerGlobal:
+		return "global"
+	default:
+		return "unknown"
+	}
+}
+
+type commandLayer struct {
+	commands  []Command
+	aliasMap  map[string]string
+	localizer *CommandLocalizer
+}
+
 type CommandRegistry struct {
-	globalCommands  []Command
-	localCommands   []Command
-	globalAliasMap  map[string]string
-	localAliasMap   map[string]string
+	layers          map[CommandLayer]*commandLayer
+	middleware      []CommandMiddleware
 	globalLocalizer *CommandLocalizer
 	localLocalizer  *CommandLocalizer
 }
 
 func NewCommandRegistry(globalLocalizer, localLocalizer *CommandLocalizer) *CommandRegistry {
+	layers := make(map[CommandLayer]*commandLayer, len(CommandLayers))
+	for _, layer := range CommandLayers {
+		// переводы встроенных команд хранятся отдельно, остальные слои делят файлы core, app и игр
+		localizer := localLocalizer
+		if layer == LayerGlobal {
+			localizer = globalLocalizer
+		}
+		layers[layer] = &commandLayer{
+			aliasMap:  make(map[string]string),
+			localizer: localizer,
+		}
+	}
 	return &CommandRegistry{
-		globalAliasMap:  make(map[string]string),
-		localAliasMap:   make(map[string]string),
+		layers:          layers,
 		globalLocalizer: globalLocalizer,
 		localLocalizer:  localLocalizer,
 	}
@@ -32,8 +79,9 @@ func (r *CommandRegistry) LoadLocalTranslations(filePath string) error {
 }
 
 func (r *CommandRegistry) UpdateAliases() {
-	r.updateGlobalAliases()
-	r.updateLocalAliases()
+	for _, layer := range CommandLayers {
+		r.updateAliases(layer)
+	}
 }
 
 func (r *CommandRegistry) containsCommand(cmds []Command, cmd Command) bool {
@@ -45,114 +93,59 @@ func (r *CommandRegistry) containsCommand(cmds []Command, cmd Command) bool {
 	return false
 }
 
-func (r *CommandRegistry) GetName(cmd Command) (string, error) {
-	cmdScope := cmd.Scope()
-	cmdId := cmd.Id()
-	if r.containsCommand(r.localCommands, cmd) {
-		if r.localLocalizer.Exists(cmdScope, cmdId) {
-			return r.localLocalizer.GetName(cmdScope, cmdId)
+// localizerOf возвращает локализатор слоя с наивысшим приоритетом, в котором зарегистрирована команда.
+func (r *CommandRegistry) localizerOf(cmd Command) (*CommandLocalizer, error) {
+	for _, layer := range CommandLayers {
+		if !r.containsCommand(r.layers[layer].commands, cmd) {
+			continue
 		}
-		return "", NewAppError(ErrLocalization, "command_not_found", map[string]any{
-			"scope": cmdScope,
-			"cmd":   cmdId,
-		})
-	}
-	if r.containsCommand(r.globalCommands, cmd) {
-		if r.globalLocalizer.Exists(cmdScope, cmdId) {
-			return r.globalLocalizer.GetName(cmdScope, cmdId)
+		if localizer := r.layers[layer].localizer; localizer.Exists(cmd.Scope(), cmd.Id()) {
+			return localizer, nil
 		}
-		return "", NewAppError(ErrLocalization, "command_not_found", map[string]any{
-			"scope": cmdScope,
-			"cmd":   cmdId,
-		})
+		break
 	}
-	return "", NewAppError(ErrLocalization, "command_not_found", map[string]any{
-		"scope": cmdScope,
-		"cmd":   cmdId,
+	return nil, NewAppError(ErrLocalization, "command_not_found", map[string]any{
+		"scope": cmd.Scope(),
+		"cmd":   cmd.Id(),
 	})
 }
 
-func (r *CommandRegistry) GetDescription(cmd Command) (string, error) {
-	cmdScope := cmd.Scope()
-	cmdId := cmd.Id()
-	if r.containsCommand(r.localCommands, cmd) {
-		if r.localLocalizer.Exists(cmdScope, cmdId) {
-			return r.localLocalizer.GetDescription(cmdScope, cmdId)
-		}
-		return "", NewAppError(ErrLocalization, "command_not_found", map[string]any{
-			"scope": cmdScope,
-			"cmd":   cmdId,
-		})
-	}
-	if r.containsCommand(r.globalCommands, cmd) {
-		if r.globalLocalizer.Exists(cmdScope, cmdId) {
-			return r.globalLocalizer.GetDescription(cmdScope, cmdId)
-		}
-		return "", NewAppError(ErrLocalization, "command_not_found", map[string]any{
-			"scope": cmdScope,
-			"cmd":   cmdId,
-		})
+func (r *CommandRegistry) GetName(cmd Command) (string, error) {
+	localizer, err := r.localizerOf(cmd)
+	if err != nil {
+		return "", err
 	}
-	return "", NewAppError(ErrLocalization, "command_not_found", map[string]any{
-		"scope": cmdScope,
-		"cmd":   cmdId,
-	})
+	return localizer.GetName(cmd.Scope(), cmd.Id())
 }
 
-func (r *CommandRegistry) GetAliases(cmd Command) ([]string, error) {
-	cmdScope := cmd.Scope()
-	cmdId := cmd.Id()
-	if r.containsCommand(r.localCommands, cmd) {
-		if r.localLocalizer.Exists(cmdScope, cmdId) {
-			return r.localLocalizer.GetAliases(cmdScope, cmdId)
-		}
-		return []string{}, NewAppError(ErrLocalization, "command_not_found", map[string]any{
-			"scope": cmdScope,
-			"cmd":   cmdId,
-		})
-	}
-	if r.containsCommand(r.globalCommands, cmd) {
-		if r.globalLocalizer.Exists(cmdScope, cmdId) {
-			return r.globalLocalizer.GetAliases(cmdScope, cmdId)
-		}
-		return []string{}, NewAppError(ErrLocalization, "command_not_found", map[string]any{
-			"scope": cmdScope,
-			"cmd":   cmdId,
-		})
+func (r *CommandRegistry) GetDescription(cmd Command) (string, error) {
+	localizer, err := r.localizerOf(cmd)
+	if err != nil {
+		return "", err
 	}
-	return []string{}, NewAppError(ErrLocalization, "command_not_found", map[string]any{
-		"scope": cmdScope,
-		"cmd":   cmdId,
-	})
+	return localizer.GetDescription(cmd.Scope(), cmd.Id())
 }
 
-func (r *CommandRegistry) updateGlobalAliases() {
-	r.globalAliasMap = make(map[string]string)
-	for _, cmd := range r.globalCommands {
-		cmdScope := cmd.Scope()
-		cmdId := cmd.Id()
-		if r.globalLocalizer.Exists(cmdScope, cmdId) {
-			name, _ := r.globalLocalizer.GetName(cmdScope, cmdId)
-			r.globalAliasMap[strings.ToLower(name)] = cmdId
-			aliases, _ := r.globalLocalizer.GetAliases(cmdScope, cmdId)
-			for _, alias := range aliases {
-				r.globalAliasMap[strings.ToLower(alias)] = cmdId
-			}
-		}
+func (r *CommandRegistry) GetAliases(cmd Command) ([]string, error) {
+	localizer, err := r.localizerOf(cmd)
+	if err != nil {
+		return []string{}, err
 	}
+	return localizer.GetAliases(cmd.Scope(), cmd.Id())
 }
 
-func (r *CommandRegistry) updateLocalAliases() {
-	r.localAliasMap = make(map[string]string)
-	for _, cmd := range r.localCommands {
+func (r *CommandRegistry) updateAliases(layer CommandLayer) {
+	l := r.layers[layer]
+	l.aliasMap = make(map[string]string)
+	for _, cmd := range l.commands {
 		cmdScope := cmd.Scope()
 		cmdId := cmd.Id()
-		if r.localLocalizer.Exists(cmdScope, cmdId) {
-			name, _ := r.localLocalizer.GetName(cmdScope, cmdId)
-			r.localAliasMap[strings.ToLower(name)] = cmdId
-			aliases, _ := r.localLocalizer.GetAliases(cmdScope, cmdId)
+		if l.localizer.Exists(cmdScope, cmdId) {
+			name, _ := l.localizer.GetName(cmdScope, cmdId)
+			l.aliasMap[strings.ToLower(name)] = cmdId
+			aliases, _ := l.localizer.GetAliases(cmdScope, cmdId)
 			for _, alias := range aliases {
-				r.localAliasMap[strings.ToLower(alias)] = cmdId
+				l.aliasMap[strings.ToLower(alias)] = cmdId
 			}
 		}
 	}
@@ -180,38 +173,88 @@ func (r *CommandRegistry) FindCommandWithoutLocalization(commandList []Command,
 	return nil
 }
 
-func (r *CommandRegistry) RegisterGlobalCommands(cmds []Command) error {
-	if cmd := r.FindCommandWithoutLocalization(cmds, r.globalLocalizer.Translations); cmd != nil {
+// RegisterCommands заменяет набор команд слоя.
+func (r *CommandRegistry) RegisterCommands(layer CommandLayer, cmds []Command) error {
+	l := r.layers[layer]
+	if cmd := r.FindCommandWithoutLocalization(cmds, l.localizer.Translations); cmd != nil {
 		return NewAppError(ErrLocalization, "command_localization_not_found", map[string]any{
 			"scope": cmd.Scope(),
 			"cmd":   cmd.Id(),
 		})
 	}
-	r.globalCommands = cmds
-	r.sortCommands(r.globalCommands)
-	r.updateGlobalAliases()
+	l.commands = cmds
+	r.sortCommands(l.commands)
+	r.updateAliases(layer)
 	return nil
 }
 
+func (r *CommandRegistry) ClearCommands(layer CommandLayer) {
+	r.layers[layer].commands = nil
+	r.updateAliases(layer)
+}
+
+func (r *CommandRegistry) RegisterGlobalCommands(cmds []Command) error {
+	return r.RegisterCommands(LayerGlobal, cmds)
+}
+
 func (r *CommandRegistry) RegisterLocalCommands(cmds []Command) error {
-	if cmd := r.FindCommandWithoutLocalization(cmds, r.localLocalizer.Translations); cmd != nil {
-		return NewAppError(ErrLocalization, "command_localization_not_found", map[string]any{
-			"scope": cmd.Scope(),
-			"cmd":   cmd.Id(),
-		})
+	return r.RegisterCommands(LayerState, cmds)
+}
+
+func (r *CommandRegistry) GetCommands(layer CommandLayer) []Command {
+	return r.layers[layer].commands
+}
+
+// IsAvailable проверяет, можно ли сейчас вызвать команду: скрытые команды доступны только в режиме отладки.
+func (r *CommandRegistry) IsAvailable(cmd Command, ctx *AppContext) bool {
+	if hidden, ok := cmd.(HiddenCommand); ok && hidden.IsHidden() && !ctx.Config.Debug {
+		return false
 	}
-	r.localCommands = cmds
-	r.sortCommands(r.localCommands)
-	r.updateLocalAliases()
-	return nil
+	if conditional, ok := cmd.(ConditionalCommand); ok {
+		return conditional.IsAvailable(ctx)
+	}
+	return true
+}
+
+// availableCommands отбирает доступные команды, сохраняя их порядок.
+func (r *CommandRegistry) availableCommands(cmds []Command, ctx *AppContext) []Command {
+	available := make([]Command, 0, len(cmds))
+	for _, cmd := range cmds {
+		if r.IsAvailable(cmd, ctx) {
+			available = append(available, cmd)
+		}
+	}
+	return available
+}
+
+// GetVisibleCommands возвращает доступные команды слоя без тех, что перекрыты доступными одноимёнными командами более приоритетных слоёв.
+func (r *CommandRegistry) GetVisibleCommands(layer CommandLayer, ctx *AppContext) []Command {
+	available := r.availableCommands(r.layers[layer].commands, ctx)
+	visible := make([]Command, 0, len(available))
+	for _, cmd := range available {
+		shadowed := false
+		for _, higher := range CommandLayers {
+			if higher == layer {
+				break
+			}
+			if r.containsCommand(r.availableCommands(r.layers[higher].commands, ctx), cmd) {
+				shadowed = true
+				break
+			}
+		}
+		if !shadowed {
+			visible = append(visible, cmd)
+		}
+	}
+	return visible
 }
 
 func (r *CommandRegistry) GetGlobalCommands() []Command {
-	return r.globalCommands
+	return r.GetCommands(LayerGlobal)
 }
 
 func (r *CommandRegistry) GetLocalCommands() []Command {
-	return r.localCommands
+	return r.GetCommands(LayerState)
 }
 
 func (r *CommandRegistry) sortCommands(cmds []Command) {
@@ -222,19 +265,23 @@ func (r *CommandRegistry) sortCommands(cmds []Command) {
 	})
 }
 
-func (r *CommandRegistry) GetCommand(input string) Command {
+// GetCommand сначала ищет точное совпадение названия или алиаса во всех слоях по порядку приоритета, затем совпадение по префиксу.
+// Недоступные сейчас команды пропускаются, и ввод достаётся команде из следующего слоя.
+func (r *CommandRegistry) GetCommand(input string, ctx *AppContext) Command {
 	input = strings.ToLower(input)
-	if cmdId, exists := r.localAliasMap[input]; exists {
-		return r.findCommandById(r.localCommands, cmdId)
-	}
-	if cmdId, exists := r.globalAliasMap[input]; exists {
-		return r.findCommandById(r.globalCommands, cmdId)
-	}
-	if cmd := r.findCommandOrAliasByPrefix(r.localCommands, r.localAliasMap, input); cmd != nil {
-		return cmd
+	for _, layer := range CommandLayers {
+		l := r.layers[layer]
+		if cmdId, exists := l.aliasMap[input]; exists {
+			if cmd := r.findCommandById(r.availableCommands(l.commands, ctx), cmdId); cmd != nil {
+				return cmd
+			}
+		}
 	}
-	if cmd := r.findCommandOrAliasByPrefix(r.globalCommands, r.globalAliasMap, input); cmd != nil {
-		return cmd
+	for _, layer := range CommandLayers {
+		l := r.layers[layer]
+		if cmd := r.findCommandOrAliasByPrefix(r.availableCommands(l.commands, ctx), l.aliasMap, input); cmd != nil {
+			return cmd
+		}
 	}
 	return nil
 }
@@ -247,7 +294,9 @@ func (r *CommandRegistry) findCommandOrAliasByPrefix(cmds []Command, aliasMap ma
 	// 2. Проверяем алиасы по префиксу
 	for alias, cmdId := range aliasMap {
 		if strings.HasPrefix(alias, prefix) {
-			return r.findCommandById(cmds, cmdId)
+			if cmd := r.findCommandById(cmds, cmdId); cmd != nil {
+				return cmd
+			}
 		}
 	}
 	return nil
@@ -267,15 +316,29 @@ func (r *CommandRegistry) findCommandByPrefix(cmds []Command, prefix string) Com
 	return nil
 }
 
-func (r *CommandRegistry) ParseInput(input string) (Command, []string) {
+func (r *CommandRegistry) ParseInput(input string, ctx *AppContext) (Command, []string) {
 	if input == "" {
 		return nil, []string{}
 	}
 	args := strings.Fields(input)
 	cmdPart := args[0]
 	args[0] = strings.ToLower(cmdPart)
-	if cmd := r.GetCommand(cmdPart); cmd != nil {
+	if cmd := r.GetCommand(cmdPart, ctx); cmd != nil {
 		return cmd, args
 	}
 	return nil, []string{}
 }
+
+// Use добавляет middleware в конец цепочки: первое добавленное выполняется первым.
+func (r *CommandRegistry) Use(middleware ...CommandMiddleware) {
+	r.middleware = append(r.middleware, middleware...)
+}
+
+// Dispatch выполняет команду через цепочку middleware.
+func (r *CommandRegistry) Dispatch(call *CommandCall) (Transition, error) {
+	handler := CommandHandler(executeCommand)
+	for i := len(r.middleware) - 1; i >= 0; i-- {
+		handler = r.middleware[i](handler)
+	}
+	return handler(call)
+}
@@ -2,6 +2,7 @@ package core
 
 import (
 	"fmt"
+	"game_hub/utils"
 	"strings"
 	"time"
 )
@@ -12,18 +13,18 @@ func (c *QuitCommand) Id() string {
 	return "quit"
 }
 
-func (c *QuitCommand) Execute(ctx *AppContext, ui *UiContext, args []string) (State, error) {
+func (c *QuitCommand) Execute(ctx *AppContext, ui *UiContext, args []string) (Transition, error) {
 	if len(args) > 1 && args[1] == "force" {
-		return &ExitState{}, nil
+		return Push(&ExitState{}), nil
 	}
 	state, err := ctx.GetCurrentState()
 	if err != nil {
-		return state, nil
+		return Stay(), nil
 	}
 	if _, ok := state.(*ConfirmationDialogState); ok {
-		return state, nil
+		return Stay(), nil
 	}
-	return NewConfirmationDialog(&ExitState{}, "quit_confirm"), nil
+	return Push(NewConfirmationDialog(&ExitState{}, "quit_confirm")), nil
 }
 
 type HelpCommand struct{ BaseCommand }
@@ -32,25 +33,32 @@ func (c *HelpCommand) Id() string {
 	return "help"
 }
 
-func (c *HelpCommand) Execute(ctx *AppContext, ui *UiContext, args []string) (State, error) {
+func (c *HelpCommand) Execute(ctx *AppContext, ui *UiContext, args []string) (Transition, error) {
 	state, err := ctx.GetCurrentState()
 	if err != nil {
-		return nil, err
+		return Stay(), err
 	}
+	// справка собирается целиком и выводится постранично: вместе с описанием состояния она может не поместиться на экран
+	var help strings.Builder
 	desc := ui.GetLocalizedStateDescription(state)
 	if desc == "" {
-		ui.DisplayText(ui.GetLocalizedMsg(ui.AppLocalizer, "help_not_found") + "\r\n")
+		help.WriteString(ui.GetLocalizedMsg(ui.AppLocalizer, "help_not_found") + "\r\n")
 	} else {
-		ui.DisplayText(fmt.Sprintf("%s\r\n", desc))
+		help.WriteString(fmt.Sprintf("%s\r\n", desc))
 	}
-	ui.DisplayText(ui.GetLocalizedMsg(ui.AppLocalizer, "available_commands") + "\r\n")
-	for _, cmd := range ui.CommandRegistry.GetLocalCommands() {
-		ui.DisplayText(fmt.Sprintf("%s: (%s).\r\n%s\r\n", ui.GetLocalizedCmdName(cmd), strings.Join(ui.GetLocalizedCmdAliases(cmd), ", "), ui.GetLocalizedCmdDescription(cmd)))
+	help.WriteString(ui.GetLocalizedMsg(ui.AppLocalizer, "available_commands") + "\r\n")
+	// команды сгруппированы по слоям; недоступные и перекрытые более приоритетным слоем не показываются
+	for _, layer := range CommandLayers {
+		cmds := ui.CommandRegistry.GetVisibleCommands(layer, ctx)
+		if len(cmds) == 0 {
+			continue
+		}
+		help.WriteString("\r\n" + ui.GetLocalizedMsg(ui.AppLocalizer, "commands_layer_"+layer.String()) + "\r\n")
+		for _, cmd := range cmds {
+			help.WriteString(fmt.Sprintf("%s: (%s).\r\n%s\r\n", ui.GetLocalizedCmdName(cmd), strings.Join(ui.GetLocalizedCmdAliases(cmd), ", "), ui.GetLocalizedCmdDescription(cmd)))
+		}
 	}
-	for _, cmd := range ui.CommandRegistry.GetGlobalCommands() {
-		ui.DisplayText(fmt.Sprintf("%s: (%s).\r\n%s\r\n", ui.GetLocalizedCmdName(cmd), strings.Join(ui.GetLocalizedCmdAliases(cmd), ", "), ui.GetLocalizedCmdDescription(cmd)))
-	}
-	return state, nil
+	return ui.DisplayPaged(help.String()), nil
 }
 
 type BackCommand struct{ BaseCommand }
@@ -59,12 +67,12 @@ func (c *BackCommand) Id() string {
 	return "back"
 }
 
-func (c *BackCommand) Execute(ctx *AppContext, ui *UiContext, args []string) (State, error) {
-	state, err := ctx.GetPreviousState()
-	if err != nil {
-		return nil, err
-	}
-	return state, nil
+func (c *BackCommand) Execute(ctx *AppContext, ui *UiContext, args []string) (Transition, error) {
+	return Pop(), nil
+}
+
+func (c *BackCommand) IsAvailable(ctx *AppContext) bool {
+	return ctx.StateStack.Len() > 1
 }
 
 type ExitCommand struct{ BaseCommand }
@@ -73,8 +81,36 @@ func (c *ExitCommand) Id() string {
 	return "exit"
 }
 
-func (c *ExitCommand) Execute(ctx *AppContext, ui *UiContext, args []string) (State, error) {
-	return ctx.Game.GetStartState(), nil
+func (c *ExitCommand) Execute(ctx *AppContext, ui *UiContext, args []string) (Transition, error) {
+	return ResetToGameStart(), nil
+}
+
+func (c *ExitCommand) IsAvailable(ctx *AppContext) bool {
+	return ctx.Game != nil
+}
+
+type SaveCommand struct{ BaseCommand }
+
+func (c *SaveCommand) Id() string {
+	return "save"
+}
+
+func (c *SaveCommand) Execute(ctx *AppContext, ui *UiContext, args []string) (Transition, error) {
+	if err := SaveGame(ctx); err != nil {
+		return Stay(), err
+	}
+	// без незаконченной партии в сохранение попадают только настройки игры
+	key := "game_settings_saved"
+	if game, ok := ctx.Game.(ResumableGame); ok && game.InProgress() {
+		key = "game_saved"
+	}
+	ui.DisplayText(ui.GetLocalizedMsg(ui.AppLocalizer, key) + "\r\n")
+	return Stay(), nil
+}
+
+func (c *SaveCommand) IsAvailable(ctx *AppContext) bool {
+	_, ok := ctx.Game.(SaveableGame)
+	return ok
 }
 
 type VersionCommand struct{ BaseCommand }
@@ -83,7 +119,7 @@ func (c *VersionCommand) Id() string {
 	return "version"
 }
 
-func (c *VersionCommand) Execute(ctx *AppContext, ui *UiContext, args []string) (State, error) {
+func (c *VersionCommand) Execute(ctx *AppContext, ui *UiContext, args []string) (Transition, error) {
 	versionMsg := ui.GetLocalizedMsg(ui.AppLocalizer, "version_info")
 	var displayTime string
 	builtTime, err := time.Parse(time.RFC3339, BuildTime)
@@ -94,7 +130,29 @@ func (c *VersionCommand) Execute(ctx *AppContext, ui *UiContext, args []string)
 	}
 	versionMsg = fmt.Sprintf(versionMsg, Version, displayTime)
 	ui.DisplayText(versionMsg + "\r\n")
-	return ctx.GetCurrentState()
+	return Stay(), nil
+}
+
+// WhereCommand показывает цепочку состояний от главного меню до текущего и строку состояния игры.
+type WhereCommand struct{ BaseCommand }
+
+func (c *WhereCommand) Id() string {
+	return "where"
+}
+
+func (c *WhereCommand) Execute(ctx *AppContext, ui *UiContext, args []string) (Transition, error) {
+	location := ui.Location(ctx)
+	if len(location.Trail) == 0 {
+		ui.DisplayText(ui.GetLocalizedMsg(ui.AppLocalizer, "where_unknown") + "\r\n")
+		return Stay(), nil
+	}
+	trail := map[string]any{"trail": location.Breadcrumbs()}
+	ui.DisplayText(utils.SubstituteParams(ui.GetLocalizedMsg(ui.AppLocalizer, "where_trail"), trail) + "\r\n")
+	if location.Status != "" {
+		status := map[string]any{"status": location.Status}
+		ui.DisplayText(utils.SubstituteParams(ui.GetLocalizedMsg(ui.AppLocalizer, "where_status"), status) + "\r\n")
+	}
+	return Stay(), nil
 }
 
 type ConfirmCommand struct{ BaseCommand }
@@ -103,17 +161,16 @@ func (c *ConfirmCommand) Id() string {
 	return "confirm"
 }
 
-func (c *ConfirmCommand) Execute(ctx *AppContext, ui *UiContext, args []string) (State, error) {
+func (c *ConfirmCommand) Execute(ctx *AppContext, ui *UiContext, args []string) (Transition, error) {
 	currentState, err := ctx.GetCurrentState()
 	if err != nil {
-		return currentState, err
+		return Stay(), err
 	}
 	confirmationState, ok := currentState.(*ConfirmationDialogState)
 	if !ok {
-		previousState, _ := ctx.GetPreviousState()
-		return previousState, NewAppError(ErrInternal, "некорректный диалог подтверждения.", nil)
+		return Pop(), NewAppError(ErrInternal, "некорректный диалог подтверждения.", nil)
 	}
-	return confirmationState.nextState, nil
+	return Replace(confirmationState.nextState), nil
 }
 
 type CancelCommand struct{ BaseCommand }
@@ -122,6 +179,29 @@ func (c *CancelCommand) Id() string {
 	return "cancel"
 }
 
-func (c *CancelCommand) Execute(ctx *AppContext, ui *UiContext, args []string) (State, error) {
-	return ctx.GetPreviousState()
+func (c *CancelCommand) Execute(ctx *AppContext, ui *UiContext, args []string) (Transition, error) {
+	return Pop(), nil
+}
+
+// StopReadingCommand закрывает постраничный просмотр; её псевдоним q совпадает с ответом на подсказку
+// и поэтому не достаётся по префиксу другой команде, например quit.
+type StopReadingCommand struct{ BaseCommand }
+
+func (c *StopReadingCommand) Id() string {
+	return "stop_reading"
+}
+
+func (c *StopReadingCommand) Execute(ctx *AppContext, ui *UiContext, args []string) (Transition, error) {
+	return Pop(), nil
+}
+
+// CrashCommand намеренно вызывает сбой, чтобы проверить восстановление и отчёты о сбоях.
+type CrashCommand struct{ DevCommand }
+
+func (c *CrashCommand) Id() string {
+	return "crash"
+}
+
+func (c *CrashCommand) Execute(ctx *AppContext, ui *UiContext, args []string) (Transition, error) {
+	panic("crash requested by the crash command")
 }
@@ -1,38 +1,255 @@
 package core
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/chzyer/readline"
 	"io"
 	"os"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 type Console interface {
 	Read() (string, error)
+	// ReadContext читает строку, пока не отменён ctx: по истечении срока возвращает ошибку ErrTimeout,
+	// при другой отмене — ErrInterrupt
+	ReadContext(ctx context.Context) (string, error)
 	Write(string) error
+	// CancelRead прерывает ожидание ввода; безопасно вызывать из других горутин
+	CancelRead()
+	// RestoreTerminal возвращает терминал в обычный режим, например после сбоя посреди чтения
+	RestoreTerminal() error
 	Close() error
 }
 
+// TextWidthProvider — необязательное расширение Console, которое знает ширину вывода.
+type TextWidthProvider interface {
+	// TextWidth возвращает ширину, по которой переносить текст; 0 — консоль переносит текст сама
+	TextWidth() int
+}
+
+// TextHeightProvider — необязательное расширение Console, которое знает высоту терминала; по ней длинный вывод делится на страницы.
+type TextHeightProvider interface {
+	// TextHeight возвращает число строк на экране; 0 — вывод не делится на страницы
+	TextHeight() int
+}
+
+// LocationPrompter — необязательное расширение Console, которое показывает в строке ввода, где находится игрок.
+type LocationPrompter interface {
+	// SetLocation задаёт текст перед "> "; если строка уже читается, приглашение обновляется сразу
+	SetLocation(location string)
+}
+
+// HistoryConsole — необязательное расширение Console с историей ввода, которую листают стрелками.
+type HistoryConsole interface {
+	// SetHistory задаёт историю, которую листает следующее чтение строки; scope отличает истории друг от друга,
+	// и уже загруженная история не загружается заново
+	SetHistory(scope string, lines []string)
+}
+
+// ColorSupport — необязательное расширение Console, которое умеет показывать стили ANSI.
+type ColorSupport interface {
+	// SupportsColor сообщает, выводит ли консоль в терминал; в файл или канал escape-последовательности не пишутся
+	SupportsColor() bool
+}
+
+type KeyCode int
+
+const (
+	// KeyRune — печатный символ, он хранится в Key.Rune
+	KeyRune KeyCode = iota
+	KeyEnter
+	KeyEscape
+	KeyBackspace
+	KeyTab
+	KeyUp
+	KeyDown
+	KeyLeft
+	KeyRight
+	KeyHome
+	KeyEnd
+)
+
+// Key — одно нажатие клавиши в посимвольном режиме.
+type Key struct {
+	Code KeyCode
+	Rune rune
+}
+
+// Digit возвращает цифру, если нажата клавиша с цифрой.
+func (k Key) Digit() (int, bool) {
+	if k.Code != KeyRune || k.Rune < '0' || k.Rune > '9' {
+		return 0, false
+	}
+	return int(k.Rune - '0'), true
+}
+
+var keyNames = map[KeyCode]string{
+	KeyEnter:     "enter",
+	KeyEscape:    "escape",
+	KeyBackspace: "backspace",
+	KeyTab:       "tab",
+	KeyUp:        "up",
+	KeyDown:      "down",
+	KeyLeft:      "left",
+	KeyRight:     "right",
+	KeyHome:      "home",
+	KeyEnd:       "end",
+}
+
+// String возвращает сам символ или название клавиши в угловых скобках, например для отчёта о сбое.
+func (k Key) String() string {
+	if k.Code == KeyRune {
+		return string(k.Rune)
+	}
+	return "<" + keyNames[k.Code] + ">"
+}
+
+// KeyReader — необязательное расширение Console для посимвольного ввода без Enter.
+type KeyReader interface {
+	// SupportsKeys сообщает, подключён ли ввод к терминалу: при вводе из файла или канала остаётся построчный режим
+	SupportsKeys() bool
+	// ReadKey ждёт одно нажатие, показывая prompt в строке ввода; отмена ctx работает так же, как в ReadContext
+	ReadKey(ctx context.Context, prompt string) (Key, error)
+	// ReadWithText читает строку, в которой уже набран text, — так клавиша, не нужная состоянию, становится началом команды
+	ReadWithText(ctx context.Context, text string) (string, error)
+}
+
+// ReadWithTimeout читает строку не дольше timeout; по его истечении возвращает ошибку ErrTimeout.
+func ReadWithTimeout(console Console, timeout time.Duration) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return console.ReadContext(ctx)
+}
+
+// cancelReason — почему прервано чтение
+type cancelReason int32
+
+const (
+	cancelNone cancelReason = iota
+	cancelInterrupt
+	cancelDeadline
+)
+
+func (r cancelReason) err() error {
+	switch r {
+	case cancelInterrupt:
+		return NewAppError(ErrInterrupt, "interrupt", nil)
+	case cancelDeadline:
+		return NewAppError(ErrTimeout, "input_timeout", nil)
+	default:
+		return nil
+	}
+}
+
+func reasonOf(ctx context.Context) cancelReason {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return cancelDeadline
+	}
+	return cancelInterrupt
+}
+
+type readMode int
+
+const (
+	readIdle readMode = iota
+	readLine
+	readKey
+)
+
+// escapeDelay — сколько ждать продолжения escape-последовательности, прежде чем считать её нажатием Escape
+const escapeDelay = 50 * time.Millisecond
+
+const linePrompt = "> "
+
+var escapeKeys = map[rune]KeyCode{
+	'A': KeyUp,
+	'B': KeyDown,
+	'C': KeyRight,
+	'D': KeyLeft,
+	'H': KeyHome,
+	'F': KeyEnd,
+}
+
 type ReadlineConsole struct {
-	rl *readline.Instance
+	rl        *readline.Instance
+	cancelled atomic.Int32
+	// raw — терминал переведён в посимвольный режим чтением клавиши
+	raw atomic.Bool
+	// mu защищает mode и readId: от них зависит, как и какое чтение прервать
+	mu     sync.Mutex
+	mode   readMode
+	readId int
+	// location — начало приглашения строки ввода, см. SetLocation
+	location string
+	// historyScope и historyLines задаёт SetHistory; historyLoaded — область истории, которую сейчас листает readline
+	historyScope  string
+	historyLines  []string
+	historyLoaded string
+	// closePending означает, что readline ещё не вернул io.EOF, отправленный при прерывании уже завершившегося чтения
+	closePending bool
+	// в посимвольном режиме фильтр readline передаёт символы сюда, минуя редактор строки
+	runes   chan rune
+	cancel  chan struct{}
+	pending []rune
 }
 
-func NewReadlineConsole() (*ReadlineConsole, error) {
+// NewReadlineConsole создаёт консоль, которая помнит до historySize строк ввода; при 0 история не ведётся.
+func NewReadlineConsole(historySize int) (*ReadlineConsole, error) {
+	c := &ReadlineConsole{
+		runes:  make(chan rune, 64),
+		cancel: make(chan struct{}, 1),
+	}
 	rl, err := readline.NewEx(&readline.Config{
-		Prompt:       "> ",
-		HistoryLimit: 200,
+		Prompt:              linePrompt,
+		HistoryLimit:        readlineHistoryLimit(historySize),
+		FuncFilterInputRune: c.filterInput,
 	})
 	if err != nil {
 		return nil, err
 	}
-	return &ReadlineConsole{rl: rl}, nil
+	c.rl = rl
+	return c, nil
 }
 
 func (c *ReadlineConsole) Read() (string, error) {
-	line, err := c.rl.Readline()
+	return c.readLine(context.Background(), "")
+}
+
+func (c *ReadlineConsole) ReadContext(ctx context.Context) (string, error) {
+	return c.readLine(ctx, "")
+}
+
+func (c *ReadlineConsole) ReadWithText(ctx context.Context, text string) (string, error) {
+	return c.readLine(ctx, text)
+}
+
+func (c *ReadlineConsole) readLine(ctx context.Context, text string) (string, error) {
+	id, err := c.begin(readLine)
+	if err != nil {
+		return "", err
+	}
+	defer c.end()
+	stop := context.AfterFunc(ctx, func() { c.cancelRead(reasonOf(ctx), id) })
+	defer stop()
+	c.discardKeys()
+	c.loadHistory()
+	c.rl.SetPrompt(c.linePrompt())
+	// после посимвольного режима readline может продолжать перерисовывать строку ввода при выводе, поэтому прячем приглашение
+	defer c.rl.SetPrompt("")
+	line, err := c.readline(text)
+	if reason := cancelReason(c.cancelled.Swap(int32(cancelNone))); reason != cancelNone {
+		// недописанная строка прерванного чтения не должна появиться в следующем
+		c.rl.SetPrompt("")
+		c.rl.Operation.SetBuffer("")
+		return "", reason.err()
+	}
 	if err == readline.ErrInterrupt {
-		return "", NewAppError(ErrEOF, "interrupt", nil)
+		return "", NewAppError(ErrInterrupt, "interrupt", nil)
 	}
 	if err == io.EOF {
 		return "", NewAppError(ErrEOF, "interrupt", nil)
@@ -45,13 +262,313 @@ func (c *ReadlineConsole) Read() (string, error) {
 	return strings.TrimSpace(line), nil
 }
 
+// readline пропускает io.EOF, оставшийся от прерывания, которое пришло уже после ввода строки.
+func (c *ReadlineConsole) readline(text string) (string, error) {
+	for {
+		line, err := c.rl.ReadlineWithDefault(text)
+		c.mu.Lock()
+		stale := err == io.EOF && c.closePending && c.cancelled.Load() == int32(cancelNone)
+		if err == io.EOF {
+			c.closePending = false
+		}
+		c.mu.Unlock()
+		if !stale {
+			return line, err
+		}
+	}
+}
+
+func (c *ReadlineConsole) SetLocation(location string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.location = location
+	if c.mode == readLine {
+		c.rl.SetPrompt(c.location + linePrompt)
+		c.rl.Refresh()
+	}
+}
+
+func (c *ReadlineConsole) SetHistory(scope string, lines []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.historyScope, c.historyLines = scope, lines
+}
+
+// loadHistory подменяет историю readline, если с прошлого чтения строки сменилась область.
+// Историю трогает только горутина чтения, поэтому она меняется перед чтением, а не в SetHistory.
+func (c *ReadlineConsole) loadHistory() {
+	c.mu.Lock()
+	scope, lines := c.historyScope, c.historyLines
+	c.mu.Unlock()
+	if scope == c.historyLoaded {
+		return
+	}
+	c.rl.ResetHistory()
+	for _, line := range lines {
+		// без файла истории readline сохраняет строки только в памяти, ошибок не бывает
+		_ = c.rl.SaveHistory(line)
+	}
+	c.historyLoaded = scope
+}
+
+func (c *ReadlineConsole) linePrompt() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.location + linePrompt
+}
+
+// readlineHistoryLimit переводит размер истории в лимит readline: ноль там означает размер по умолчанию, а отрицательный — отключённую историю.
+func readlineHistoryLimit(historySize int) int {
+	if historySize == 0 {
+		return -1
+	}
+	return historySize
+}
+
 func (c *ReadlineConsole) Write(s string) error {
-	if _, err := os.Stdout.WriteString(s); err != nil {
+	// запись через readline перерисовывает строку ввода, если вывод пришёл во время чтения
+	if _, err := c.rl.Write([]byte(s)); err != nil {
 		return err
 	}
 	return nil
 }
 
+// CancelRead закрывает только операцию чтения: в отличие от Close, терминал остаётся готовым к следующему вводу.
+// Если сейчас ничего не читается, прерванным окажется следующее чтение.
+func (c *ReadlineConsole) CancelRead() {
+	c.cancelRead(cancelInterrupt, 0)
+}
+
+// cancelRead прерывает чтение с номером id; нулевой id означает текущее или следующее чтение.
+// Прерывание по сигналу важнее истёкшего срока и не заменяется им.
+func (c *ReadlineConsole) cancelRead(reason cancelReason, id int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if id != 0 && (id != c.readId || c.mode == readIdle) {
+		// срок относится к уже завершённому чтению
+		return
+	}
+	if reason == cancelInterrupt {
+		c.cancelled.Store(int32(reason))
+	} else if !c.cancelled.CompareAndSwap(int32(cancelNone), int32(reason)) {
+		return
+	}
+	switch c.mode {
+	case readLine:
+		c.rl.Operation.Close()
+		c.closePending = true
+	case readKey:
+		select {
+		case c.cancel <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// begin отмечает начало чтения и возвращает его номер; ошибка означает, что чтение прервали заранее.
+func (c *ReadlineConsole) begin(mode readMode) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if reason := cancelReason(c.cancelled.Swap(int32(cancelNone))); reason != cancelNone {
+		return 0, reason.err()
+	}
+	c.mode = mode
+	c.readId++
+	return c.readId, nil
+}
+
+func (c *ReadlineConsole) end() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.mode = readIdle
+	select {
+	case <-c.cancel:
+	default:
+	}
+}
+
+// TextWidth возвращает текущую ширину терминала или defaultTextWidth, если вывод идёт не в терминал.
+func (c *ReadlineConsole) TextWidth() int {
+	if width := readline.GetScreenWidth(); width > 0 {
+		return width
+	}
+	return defaultTextWidth
+}
+
+func (c *ReadlineConsole) TextHeight() int {
+	fd := int(os.Stdout.Fd())
+	if !readline.IsTerminal(fd) {
+		return 0
+	}
+	if _, height, err := readline.GetSize(fd); err == nil {
+		return height
+	}
+	return 0
+}
+
+func (c *ReadlineConsole) SupportsColor() bool {
+	return readline.IsTerminal(int(os.Stdout.Fd()))
+}
+
+func (c *ReadlineConsole) SupportsKeys() bool {
+	return readline.DefaultIsTerminal()
+}
+
+func (c *ReadlineConsole) ReadKey(ctx context.Context, prompt string) (Key, error) {
+	id, err := c.begin(readKey)
+	if err != nil {
+		return Key{}, err
+	}
+	defer c.end()
+	stop := context.AfterFunc(ctx, func() { c.cancelRead(reasonOf(ctx), id) })
+	defer stop()
+	// в режиме vim readline отдаёт Escape сразу, не дожидаясь следующего символа; последовательности разбирает nextKey
+	c.rl.SetVimMode(true)
+	defer c.rl.SetVimMode(false)
+	if err := c.rl.Terminal.EnterRawMode(); err != nil {
+		return Key{}, NewAppError(ErrInternal, "read_error", map[string]any{
+			"error": fmt.Sprintf("%v", err),
+		})
+	}
+	c.raw.Store(true)
+	defer c.exitRawMode()
+	c.rl.SetPrompt(prompt)
+	c.rl.Operation.SetBuffer("")
+	c.rl.Terminal.KickRead()
+	key, err := c.nextKey()
+	// убираем строку ввода, чтобы следующий вывод начинался с чистой строки
+	c.rl.Clean()
+	c.rl.SetPrompt("")
+	return key, err
+}
+
+// discardKeys отбрасывает клавиши, нажатые после последнего посимвольного чтения: строке они уже не нужны.
+func (c *ReadlineConsole) discardKeys() {
+	c.pending = nil
+	for {
+		select {
+		case <-c.runes:
+		default:
+			return
+		}
+	}
+}
+
+// filterInput перехватывает символы в посимвольном режиме; в построчном они достаются редактору readline.
+func (c *ReadlineConsole) filterInput(r rune) (rune, bool) {
+	c.mu.Lock()
+	mode := c.mode
+	c.mu.Unlock()
+	if mode != readKey {
+		return r, true
+	}
+	select {
+	case c.runes <- r:
+	default:
+	}
+	// конец ввода должен дойти и до readline, иначе он будет снова и снова читать закрытый поток
+	return r, r == 0
+}
+
+// nextRune ждёт символ не дольше timeout; нулевой timeout означает ожидание без ограничения.
+func (c *ReadlineConsole) nextRune(timeout time.Duration) (r rune, ok bool, err error) {
+	if len(c.pending) > 0 {
+		r, c.pending = c.pending[0], c.pending[1:]
+		return r, true, nil
+	}
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+	select {
+	case r = <-c.runes:
+		return r, true, nil
+	case <-expired:
+		return 0, false, nil
+	case <-c.cancel:
+		reason := cancelReason(c.cancelled.Swap(int32(cancelNone)))
+		if reason == cancelNone {
+			reason = cancelInterrupt
+		}
+		return 0, false, reason.err()
+	}
+}
+
+func (c *ReadlineConsole) nextKey() (Key, error) {
+	for {
+		r, _, err := c.nextRune(0)
+		if err != nil {
+			return Key{}, err
+		}
+		switch r {
+		case 0, readline.CharDelete:
+			return Key{}, NewAppError(ErrEOF, "interrupt", nil)
+		case readline.CharInterrupt:
+			return Key{}, NewAppError(ErrInterrupt, "interrupt", nil)
+		case '\r', '\n':
+			return Key{Code: KeyEnter}, nil
+		case '\t':
+			return Key{Code: KeyTab}, nil
+		case readline.CharBackspace, '\b':
+			return Key{Code: KeyBackspace}, nil
+		case readline.CharEsc:
+			key, ok, err := c.escapeSequence()
+			if err != nil || ok {
+				return key, err
+			}
+			// неизвестные последовательности пропускаем
+			continue
+		}
+		if r >= ' ' {
+			return Key{Code: KeyRune, Rune: r}, nil
+		}
+	}
+}
+
+// escapeSequence разбирает то, что пришло после Escape: стрелки и Home/End приходят последовательностями
+// ESC [ A или ESC O A, а одиночный Escape узнаётся по тому, что за ним ничего не последовало.
+func (c *ReadlineConsole) escapeSequence() (Key, bool, error) {
+	r, ok, err := c.nextRune(escapeDelay)
+	if err != nil {
+		return Key{}, false, err
+	}
+	if !ok {
+		return Key{Code: KeyEscape}, true, nil
+	}
+	if r != '[' && r != 'O' {
+		// Alt с клавишей считаем нажатием Escape, а сам символ оставляем для следующего чтения
+		c.pending = append(c.pending, r)
+		return Key{Code: KeyEscape}, true, nil
+	}
+	for {
+		r, ok, err = c.nextRune(escapeDelay)
+		if err != nil || !ok {
+			return Key{}, false, err
+		}
+		// параметры последовательности — цифры и ';', клавишу определяет завершающий символ
+		if r >= 0x40 && r <= 0x7e {
+			break
+		}
+	}
+	code, exists := escapeKeys[r]
+	return Key{Code: code}, exists, nil
+}
+
+// RestoreTerminal выходит из посимвольного режима, только если в него вошло посимвольное чтение.
+func (c *ReadlineConsole) RestoreTerminal() error {
+	return c.exitRawMode()
+}
+
+// exitRawMode выходит из посимвольного режима один раз, кто бы ни вызвал его первым: чтение или восстановление после сбоя.
+func (c *ReadlineConsole) exitRawMode() error {
+	if !c.raw.CompareAndSwap(true, false) {
+		return nil
+	}
+	return c.rl.Terminal.ExitRawMode()
+}
+
 func (c *ReadlineConsole) Close() error {
 	return c.rl.Close()
 }
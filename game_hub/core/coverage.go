@@ -0,0 +1,83 @@
+package core
+
+import (
+	"os"
+	"strings"
+)
+
+type TranslationCoverage struct {
+	Total      int
+	Translated map[string]int
+}
+
+func NewTranslationCoverage() *TranslationCoverage {
+	return &TranslationCoverage{
+		Translated: make(map[string]int),
+	}
+}
+
+func (c *TranslationCoverage) Add(other *TranslationCoverage) {
+	c.Total += other.Total
+	for lang, count := range other.Translated {
+		c.Translated[lang] += count
+	}
+}
+
+func (c *TranslationCoverage) Percent(lang string) int {
+	if c.Total == 0 {
+		return 100
+	}
+	return c.Translated[lang] * 100 / c.Total
+}
+
+func (c *TranslationCoverage) IsComplete(lang string) bool {
+	return c.Translated[lang] >= c.Total
+}
+
+// HubCoverage подсчитывает полноту переводов ядра и приложения.
+func (lm *LocalizationManager) HubCoverage() (*TranslationCoverage, error) {
+	return lm.filesCoverage(lm.cfg.Paths.HubDataPaths())
+}
+
+// GameCoverage подсчитывает полноту переводов конкретной игры.
+func (lm *LocalizationManager) GameCoverage(gameId string) (*TranslationCoverage, error) {
+	return lm.filesCoverage(lm.cfg.Paths.GameDataPaths(gameId))
+}
+
+func (lm *LocalizationManager) filesCoverage(filePaths []string) (*TranslationCoverage, error) {
+	coverage := NewTranslationCoverage()
+	for _, filePath := range filePaths {
+		if _, err := os.Stat(filePath); os.IsNotExist(err) {
+			continue
+		}
+		fileCoverage, err := lm.fileCoverage(filePath)
+		if err != nil {
+			return nil, err
+		}
+		coverage.Add(fileCoverage)
+	}
+	return coverage, nil
+}
+
+func (lm *LocalizationManager) fileCoverage(filePath string) (*TranslationCoverage, error) {
+	root, err := lm.loadDataNode(filePath)
+	if err != nil {
+		return nil, err
+	}
+	coverage := NewTranslationCoverage()
+	for _, unit := range lm.TranslationUnits(root) {
+		coverage.Total++
+		for _, lang := range unit.Node.Keys {
+			if isTranslated(unit.Node.Fields[lang]) {
+				coverage.Translated[lang]++
+			}
+		}
+	}
+	return coverage, nil
+}
+
+// isTranslated не засчитывает пустые строки: так выглядят ключи, заготовленные для переводчика.
+// Пустой список псевдонимов — законное значение, поэтому он считается переведённым.
+func isTranslated(node *DataNode) bool {
+	return node.Kind != NodeString || strings.TrimSpace(node.Value) != ""
+}
@@ -0,0 +1,88 @@
+package core
+
+import (
+	"fmt"
+	"game_hub/config"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// CrashReportInputs ограничивает число последних строк ввода, попадающих в отчёт о сбое.
+const CrashReportInputs = 20
+
+// CrashReport собирает сведения, нужные чтобы воспроизвести сбой.
+type CrashReport struct {
+	Time     time.Time
+	Panic    any
+	Stack    []byte
+	States   []string
+	Inputs   []string
+	Language string
+	Seed     int64
+}
+
+func NewCrashReport(ctx *AppContext, ui *UiContext, recovered any, stack []byte, inputs []string) *CrashReport {
+	return &CrashReport{
+		Time:     time.Now(),
+		Panic:    recovered,
+		Stack:    stack,
+		States:   ctx.StateStack.Ids(),
+		Inputs:   inputs,
+		Language: ui.LocalizationManager.CurrentLang(),
+		Seed:     SessionSeed(),
+	}
+}
+
+func (r *CrashReport) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s (built %s)\n", AppName, Version, BuildTime)
+	fmt.Fprintf(&b, "time: %s\n", r.Time.Format(time.RFC3339))
+	fmt.Fprintf(&b, "panic: %v\n", r.Panic)
+	fmt.Fprintf(&b, "language: %s\n", r.Language)
+	fmt.Fprintf(&b, "seed: %d\n", r.Seed)
+	fmt.Fprintf(&b, "states: %s\n", strings.Join(r.States, " > "))
+	b.WriteString("last inputs:\n")
+	for _, input := range r.Inputs {
+		fmt.Fprintf(&b, "  %q\n", input)
+	}
+	b.WriteString("\n")
+	b.Write(r.Stack)
+	return b.String()
+}
+
+// crashReportAttempts ограничивает число суффиксов, которые перебираются, если отчёт с таким именем уже есть.
+const crashReportAttempts = 100
+
+// WriteCrashReport сохраняет отчёт в папку пользователя и возвращает путь к нему.
+// Существующие отчёты не перезаписываются: к имени отчёта, совпавшего по времени с другим, добавляется номер.
+func WriteCrashReport(cfg *config.Config, report *CrashReport) (string, error) {
+	name := "crash-" + report.Time.Format("20060102-150405.000")
+	filePath := cfg.Paths.CrashReportPath(name + ".txt")
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return "", crashWriteError(filePath, err)
+	}
+	for attempt := 2; ; attempt++ {
+		file, err := os.OpenFile(filePath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if os.IsExist(err) && attempt <= crashReportAttempts {
+			filePath = cfg.Paths.CrashReportPath(fmt.Sprintf("%s-%d.txt", name, attempt))
+			continue
+		}
+		if err != nil {
+			return "", crashWriteError(filePath, err)
+		}
+		_, err = file.WriteString(report.String())
+		if err := JoinErrors(err, file.Close()); err != nil {
+			return "", crashWriteError(filePath, err)
+		}
+		return filePath, nil
+	}
+}
+
+func crashWriteError(filePath string, err error) error {
+	return NewAppError(Err, "file_write_error", map[string]any{
+		"file":  filePath,
+		"error": err,
+	})
+}
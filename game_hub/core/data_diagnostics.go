@@ -0,0 +1,275 @@
+package core
+
+import (
+	"errors"
+	"github.com/go-playground/validator/v10"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type dataDiagnostic struct {
+	line   int
+	column int
+	path   string
+	err    error
+}
+
+// DataDiagnostics собирает все проблемы одного файла данных, чтобы сообщить о них разом.
+type DataDiagnostics struct {
+	file  string
+	root  *DataNode
+	items []dataDiagnostic
+}
+
+func NewDataDiagnostics(filePath string, root *DataNode) *DataDiagnostics {
+	return &DataDiagnostics{
+		file:  filePath,
+		root:  root,
+		items: make([]dataDiagnostic, 0),
+	}
+}
+
+// Add привязывает проблему к ближайшему существующему узлу на пути: у отсутствующего ключа есть только родитель.
+func (d *DataDiagnostics) Add(path []string, err error) {
+	var line, column int
+	if d.root != nil {
+		line, column = d.root.Line, d.root.Column
+	}
+	node := d.root
+	for _, key := range path {
+		node = node.Find([]string{key})
+		if node == nil {
+			break
+		}
+		if node.Line > 0 {
+			line, column = node.Line, node.Column
+		}
+	}
+	d.items = append(d.items, dataDiagnostic{
+		line:   line,
+		column: column,
+		path:   strings.Join(path, "."),
+		err:    err,
+	})
+}
+
+func (d *DataDiagnostics) Len() int {
+	return len(d.items)
+}
+
+// Err возвращает проблемы в порядке их появления в файле или nil, если проблем нет.
+func (d *DataDiagnostics) Err() error {
+	if len(d.items) == 0 {
+		return nil
+	}
+	sort.SliceStable(d.items, func(i, j int) bool {
+		a, b := d.items[i], d.items[j]
+		if a.line != b.line {
+			return a.line < b.line
+		}
+		if a.column != b.column {
+			return a.column < b.column
+		}
+		return a.path < b.path
+	})
+	errs := make([]error, 0, len(d.items))
+	for _, item := range d.items {
+		key := "data_diagnostic"
+		switch {
+		case item.line == 0:
+			key = "data_diagnostic_no_position"
+		case item.column == 0:
+			key = "data_diagnostic_at_line"
+		}
+		errs = append(errs, NewAppError(Err, key, map[string]any{
+			"file":   d.file,
+			"line":   item.line,
+			"column": item.column,
+			"path":   item.path,
+			"error":  item.err,
+		}))
+	}
+	return NewAppErrors(errs)
+}
+
+// checkNodeType сверяет дерево с типом, в который оно будет декодировано, не останавливаясь на первой ошибке.
+func (d *DataDiagnostics) checkNodeType(node *DataNode, t reflect.Type, path []string) {
+	t = indirect(t)
+	if node.Kind == NodeNull {
+		return
+	}
+	switch t.Kind() {
+	case reflect.Interface:
+	case reflect.Struct:
+		if !d.expectKind(node, NodeObject, path) {
+			return
+		}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			// поля встроенной структуры без тега лежат в том же объекте, как их читает encoding/json
+			if field.Anonymous && field.Tag.Get("json") == "" && indirect(field.Type).Kind() == reflect.Struct {
+				d.checkNodeType(node, field.Type, path)
+				continue
+			}
+			name := jsonFieldName(field)
+			if name == "" {
+				continue
+			}
+			if value := node.Get(name); value != nil {
+				d.checkNodeType(value, field.Type, childPath(path, name))
+			}
+		}
+	case reflect.Map:
+		if !d.expectKind(node, NodeObject, path) {
+			return
+		}
+		for _, key := range node.Keys {
+			d.checkNodeType(node.Fields[key], t.Elem(), childPath(path, key))
+		}
+	case reflect.Slice, reflect.Array:
+		if !d.expectKind(node, NodeArray, path) {
+			return
+		}
+		for i, item := range node.Items {
+			d.checkNodeType(item, t.Elem(), childPath(path, strconv.Itoa(i)))
+		}
+	case reflect.String:
+		d.expectKind(node, NodeString, path)
+	case reflect.Bool:
+		d.expectKind(node, NodeBool, path)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if d.expectKind(node, NodeNumber, path) && strings.ContainsAny(node.Value, ".eE") {
+			d.Add(path, NewAppError(Err, "data_type_mismatch", map[string]any{
+				"expected": NewAppError(Err, "data_kind_integer", nil),
+				"found":    node.Value,
+			}))
+		}
+	case reflect.Float32, reflect.Float64:
+		d.expectKind(node, NodeNumber, path)
+	}
+}
+
+func (d *DataDiagnostics) expectKind(node *DataNode, expected NodeKind, path []string) bool {
+	if node.Kind == expected {
+		return true
+	}
+	d.Add(path, NewAppError(Err, "data_type_mismatch", map[string]any{
+		"expected": nodeKindName(expected),
+		"found":    nodeKindName(node.Kind),
+	}))
+	return false
+}
+
+func nodeKindName(kind NodeKind) *AppError {
+	keys := map[NodeKind]string{
+		NodeNull:   "data_kind_null",
+		NodeBool:   "data_kind_boolean",
+		NodeNumber: "data_kind_number",
+		NodeString: "data_kind_string",
+		NodeArray:  "data_kind_array",
+		NodeObject: "data_kind_object",
+	}
+	return NewAppError(Err, keys[kind], nil)
+}
+
+func indirect(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+func jsonFieldName(field reflect.StructField) string {
+	if !field.IsExported() {
+		return ""
+	}
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return field.Name
+	}
+	return name
+}
+
+var validatorIndexPattern = regexp.MustCompile(`\[([^\]]*)\]`)
+
+// addValidationErrors переводит ошибки валидатора в пути файла данных с понятными пояснениями.
+func (d *DataDiagnostics) addValidationErrors(err error) {
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		d.Add([]string{}, NewAppError(Err, "decode_error", map[string]any{
+			"error": err,
+		}))
+		return
+	}
+	for _, fieldErr := range validationErrs {
+		d.Add(validatorPath(fieldErr.Namespace()), validationExplanation(fieldErr))
+	}
+}
+
+// validatorPath превращает "LangDictData.languages[en]" в путь ["languages", "en"].
+func validatorPath(namespace string) []string {
+	namespace = validatorIndexPattern.ReplaceAllString(namespace, ".$1")
+	parts := strings.Split(namespace, ".")
+	if len(parts) <= 1 {
+		return []string{}
+	}
+	return parts[1:]
+}
+
+func validationExplanation(fieldErr validator.FieldError) *AppError {
+	details := map[string]any{
+		"param": fieldErr.Param(),
+		"tag":   fieldErr.Tag(),
+	}
+	switch fieldErr.Tag() {
+	case "required":
+		return NewAppError(Err, "validation_required", details)
+	case "min":
+		if isCollection(fieldErr.Kind()) {
+			return NewAppError(Err, "validation_min_items", details)
+		}
+		return NewAppError(Err, "validation_min", details)
+	case "max":
+		if isCollection(fieldErr.Kind()) {
+			return NewAppError(Err, "validation_max_items", details)
+		}
+		return NewAppError(Err, "validation_max", details)
+	case "oneof":
+		return NewAppError(Err, "validation_oneof", details)
+	default:
+		return NewAppError(Err, "validation_failed", details)
+	}
+}
+
+func isCollection(kind reflect.Kind) bool {
+	return kind == reflect.Slice || kind == reflect.Array || kind == reflect.Map
+}
+
+func childPath(path []string, keys ...string) []string {
+	return append(append(make([]string, 0, len(path)+len(keys)), path...), keys...)
+}
+
+func missingTranslation(lang string) *AppError {
+	return NewAppError(Err, "missing_translation", map[string]any{
+		"lang": lang,
+	})
+}
+
+func missingTranslationsError(err error) *AppError {
+	return NewAppError(ErrLocalization, "missing_translations", map[string]any{
+		"error": err,
+	})
+}
+
+func invalidScope(scope Scope) *AppError {
+	return NewAppError(Err, "invalid_scope", map[string]any{
+		"scope": scope,
+	})
+}
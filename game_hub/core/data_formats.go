@@ -0,0 +1,104 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+type DataFormat string
+
+const (
+	FormatJSON DataFormat = "json"
+	FormatYAML DataFormat = "yaml"
+	FormatTOML DataFormat = "toml"
+)
+
+func DataFormatOf(filePath string) DataFormat {
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".yaml", ".yml":
+		return FormatYAML
+	case ".toml":
+		return FormatTOML
+	default:
+		return FormatJSON
+	}
+}
+
+// ParseDataFile разбирает файл данных в дерево, выбирая формат по расширению файла.
+func ParseDataFile(filePath string, data []byte) (*DataNode, error) {
+	switch DataFormatOf(filePath) {
+	case FormatYAML:
+		return parseYAMLNode(data)
+	case FormatTOML:
+		return parseTOMLNode(data)
+	default:
+		return ParseDataNode(data)
+	}
+}
+
+// EncodeDataFile сериализует дерево в формате, соответствующем расширению файла.
+func EncodeDataFile(filePath string, node *DataNode) ([]byte, error) {
+	switch DataFormatOf(filePath) {
+	case FormatYAML:
+		return encodeYAMLNode(node)
+	case FormatTOML:
+		return encodeTOMLNode(node)
+	default:
+		return node.Encode(), nil
+	}
+}
+
+type sourcePositions struct {
+	data       []byte
+	lineStarts []int
+}
+
+func newSourcePositions(data []byte) *sourcePositions {
+	lineStarts := []int{0}
+	for i, b := range data {
+		if b == '\n' {
+			lineStarts = append(lineStarts, i+1)
+		}
+	}
+	return &sourcePositions{data: data, lineStarts: lineStarts}
+}
+
+func (s *sourcePositions) position(offset int) (int, int) {
+	if offset > len(s.data) {
+		offset = len(s.data)
+	}
+	line := sort.Search(len(s.lineStarts), func(i int) bool {
+		return s.lineStarts[i] > offset
+	})
+	lineStart := s.lineStarts[line-1]
+	return line, utf8.RuneCount(s.data[lineStart:offset]) + 1
+}
+
+func (s *sourcePositions) decodeError(err error) error {
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		line, column := s.position(int(syntaxErr.Offset))
+		return positionError(line, column, err)
+	}
+	return NewAppError(Err, "decode_error", map[string]any{
+		"error": err,
+	})
+}
+
+func positionError(line, column int, err any) error {
+	if column == 0 {
+		return NewAppError(Err, "decode_error_at_line", map[string]any{
+			"line":  line,
+			"error": err,
+		})
+	}
+	return NewAppError(Err, "decode_error_at", map[string]any{
+		"line":   line,
+		"column": column,
+		"error":  err,
+	})
+}
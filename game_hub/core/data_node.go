@@ -0,0 +1,270 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+)
+
+type NodeKind int
+
+const (
+	NodeNull NodeKind = iota
+	NodeBool
+	NodeNumber
+	NodeString
+	NodeArray
+	NodeObject
+)
+
+// DataNode представляет документ с данными в виде дерева, сохраняющего порядок ключей.
+type DataNode struct {
+	Kind   NodeKind
+	Value  string
+	Keys   []string
+	Fields map[string]*DataNode
+	Items  []*DataNode
+	Line   int
+	Column int
+}
+
+func NewObjectNode() *DataNode {
+	return &DataNode{
+		Kind:   NodeObject,
+		Keys:   make([]string, 0),
+		Fields: make(map[string]*DataNode),
+	}
+}
+
+func NewArrayNode(items []*DataNode) *DataNode {
+	return &DataNode{Kind: NodeArray, Items: items}
+}
+
+func NewStringNode(value string) *DataNode {
+	return &DataNode{Kind: NodeString, Value: value}
+}
+
+func NewBoolNode(value bool) *DataNode {
+	if value {
+		return &DataNode{Kind: NodeBool, Value: "true"}
+	}
+	return &DataNode{Kind: NodeBool, Value: "false"}
+}
+
+func (n *DataNode) Get(key string) *DataNode {
+	if n == nil || n.Kind != NodeObject {
+		return nil
+	}
+	return n.Fields[key]
+}
+
+// Set заменяет значение существующего ключа на месте или добавляет новый ключ в конец объекта.
+func (n *DataNode) Set(key string, value *DataNode) {
+	if _, exists := n.Fields[key]; !exists {
+		n.Keys = append(n.Keys, key)
+	}
+	n.Fields[key] = value
+}
+
+// Find возвращает узел по пути из ключей объектов и индексов массивов.
+func (n *DataNode) Find(path []string) *DataNode {
+	node := n
+	for _, key := range path {
+		if node == nil {
+			return nil
+		}
+		if node.Kind == NodeArray {
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(node.Items) {
+				return nil
+			}
+			node = node.Items[index]
+			continue
+		}
+		node = node.Get(key)
+	}
+	return node
+}
+
+func (n *DataNode) Strings() []string {
+	values := make([]string, 0, len(n.Items))
+	for _, item := range n.Items {
+		if item.Kind == NodeString {
+			values = append(values, item.Value)
+		}
+	}
+	return values
+}
+
+func ParseDataNode(data []byte) (*DataNode, error) {
+	parser := &jsonNodeParser{
+		data:   data,
+		dec:    json.NewDecoder(bytes.NewReader(data)),
+		source: newSourcePositions(data),
+	}
+	parser.dec.UseNumber()
+	node, err := parser.parse()
+	if err == nil {
+		if _, tokErr := parser.dec.Token(); tokErr != io.EOF {
+			err = &json.SyntaxError{Offset: parser.dec.InputOffset()}
+		}
+	}
+	if err != nil {
+		return nil, parser.source.decodeError(err)
+	}
+	return node, nil
+}
+
+type jsonNodeParser struct {
+	data   []byte
+	dec    *json.Decoder
+	source *sourcePositions
+}
+
+// tokenStart пропускает пробелы и разделители, чтобы получить начало следующего токена.
+func (p *jsonNodeParser) tokenStart() int {
+	offset := int(p.dec.InputOffset())
+	for offset < len(p.data) {
+		switch p.data[offset] {
+		case ' ', '\t', '\r', '\n', ',', ':':
+			offset++
+		default:
+			return offset
+		}
+	}
+	return offset
+}
+
+func (p *jsonNodeParser) parse() (*DataNode, error) {
+	line, column := p.source.position(p.tokenStart())
+	tok, err := p.dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	node, err := p.parseToken(tok)
+	if err != nil {
+		return nil, err
+	}
+	node.Line, node.Column = line, column
+	return node, nil
+}
+
+func (p *jsonNodeParser) parseToken(tok json.Token) (*DataNode, error) {
+	switch value := tok.(type) {
+	case json.Delim:
+		if value == '[' {
+			node := NewArrayNode(make([]*DataNode, 0))
+			for p.dec.More() {
+				item, err := p.parse()
+				if err != nil {
+					return nil, err
+				}
+				node.Items = append(node.Items, item)
+			}
+			_, err := p.dec.Token()
+			return node, err
+		}
+		node := NewObjectNode()
+		for p.dec.More() {
+			keyTok, err := p.dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			child, err := p.parse()
+			if err != nil {
+				return nil, err
+			}
+			node.Set(keyTok.(string), child)
+		}
+		_, err := p.dec.Token()
+		return node, err
+	case string:
+		return NewStringNode(value), nil
+	case json.Number:
+		return &DataNode{Kind: NodeNumber, Value: value.String()}, nil
+	case bool:
+		return NewBoolNode(value), nil
+	default:
+		return &DataNode{Kind: NodeNull}, nil
+	}
+}
+
+// Encode сериализует дерево в JSON в том же виде, в котором написаны файлы данных.
+func (n *DataNode) Encode() []byte {
+	var buf bytes.Buffer
+	n.encode(&buf, 0)
+	buf.WriteString("\n")
+	return buf.Bytes()
+}
+
+func (n *DataNode) encode(buf *bytes.Buffer, depth int) {
+	indent := strings.Repeat("  ", depth)
+	switch n.Kind {
+	case NodeObject:
+		if len(n.Keys) == 0 {
+			buf.WriteString("{}")
+			return
+		}
+		buf.WriteString("{\n")
+		for i, key := range n.Keys {
+			buf.WriteString(indent + "  ")
+			buf.WriteString(encodeJSONString(key))
+			buf.WriteString(": ")
+			n.Fields[key].encode(buf, depth+1)
+			if i < len(n.Keys)-1 {
+				buf.WriteString(",")
+			}
+			buf.WriteString("\n")
+		}
+		buf.WriteString(indent + "}")
+	case NodeArray:
+		if n.isFlat() {
+			buf.WriteString("[")
+			for i, item := range n.Items {
+				if i > 0 {
+					buf.WriteString(", ")
+				}
+				item.encode(buf, depth+1)
+			}
+			buf.WriteString("]")
+			return
+		}
+		buf.WriteString("[\n")
+		for i, item := range n.Items {
+			buf.WriteString(indent + "  ")
+			item.encode(buf, depth+1)
+			if i < len(n.Items)-1 {
+				buf.WriteString(",")
+			}
+			buf.WriteString("\n")
+		}
+		buf.WriteString(indent + "]")
+	case NodeString:
+		buf.WriteString(encodeJSONString(n.Value))
+	case NodeNumber, NodeBool:
+		buf.WriteString(n.Value)
+	default:
+		buf.WriteString("null")
+	}
+}
+
+func (n *DataNode) isFlat() bool {
+	for _, item := range n.Items {
+		if item.Kind == NodeObject || item.Kind == NodeArray {
+			return false
+		}
+	}
+	return true
+}
+
+func encodeJSONString(s string) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(s); err != nil {
+		return `""`
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
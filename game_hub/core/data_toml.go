@@ -0,0 +1,247 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/pelletier/go-toml/v2"
+	"github.com/pelletier/go-toml/v2/unstable"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type tomlKeyPosition struct {
+	order  int
+	line   int
+	column int
+}
+
+func parseTOMLNode(data []byte) (*DataNode, error) {
+	var raw map[string]any
+	if err := toml.Unmarshal(data, &raw); err != nil {
+		var decodeErr *toml.DecodeError
+		if errors.As(err, &decodeErr) {
+			line, column := decodeErr.Position()
+			return nil, positionError(line, column, err)
+		}
+		return nil, NewAppError(Err, "decode_error", map[string]any{
+			"error": err,
+		})
+	}
+	return convertTOMLValue(raw, []string{}, tomlKeyPositions(data)), nil
+}
+
+// tomlKeyPositions запоминает порядок и позиции ключей, которые теряются при разборе TOML в map.
+func tomlKeyPositions(data []byte) map[string]tomlKeyPosition {
+	positions := make(map[string]tomlKeyPosition)
+	parser := unstable.Parser{}
+	parser.Reset(data)
+	record := func(path []string, node *unstable.Node) {
+		shape := parser.Shape(node.Raw)
+		for i := 1; i <= len(path); i++ {
+			key := strings.Join(path[:i], "\x00")
+			if _, exists := positions[key]; !exists {
+				positions[key] = tomlKeyPosition{order: len(positions), line: shape.Start.Line, column: shape.Start.Column}
+			}
+		}
+	}
+	var recordValue func(path []string, value *unstable.Node)
+	recordValue = func(path []string, value *unstable.Node) {
+		switch value.Kind {
+		case unstable.InlineTable:
+			it := value.Children()
+			for it.Next() {
+				keyValue := it.Node()
+				keyPath, keyNode := tomlKeyPath(path, keyValue.Key())
+				record(keyPath, keyNode)
+				recordValue(keyPath, keyValue.Value())
+			}
+		case unstable.Array:
+			it := value.Children()
+			for index := 0; it.Next(); index++ {
+				itemPath := append(append([]string{}, path...), strconv.Itoa(index))
+				record(itemPath, it.Node())
+				recordValue(itemPath, it.Node())
+			}
+		}
+	}
+	table := []string{}
+	for parser.NextExpression() {
+		expr := parser.Expression()
+		switch expr.Kind {
+		case unstable.Table, unstable.ArrayTable:
+			var keyNode *unstable.Node
+			table, keyNode = tomlKeyPath([]string{}, expr.Key())
+			record(table, keyNode)
+		case unstable.KeyValue:
+			keyPath, keyNode := tomlKeyPath(table, expr.Key())
+			record(keyPath, keyNode)
+			recordValue(keyPath, expr.Value())
+		}
+	}
+	return positions
+}
+
+func tomlKeyPath(prefix []string, it unstable.Iterator) ([]string, *unstable.Node) {
+	path := append([]string{}, prefix...)
+	var first *unstable.Node
+	for it.Next() {
+		if first == nil {
+			first = it.Node()
+		}
+		path = append(path, string(it.Node().Data))
+	}
+	return path, first
+}
+
+func convertTOMLValue(value any, path []string, positions map[string]tomlKeyPosition) *DataNode {
+	var node *DataNode
+	switch v := value.(type) {
+	case map[string]any:
+		node = NewObjectNode()
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		order := func(key string) int {
+			if position, exists := positions[strings.Join(append(append([]string{}, path...), key), "\x00")]; exists {
+				return position.order
+			}
+			return len(positions)
+		}
+		sort.SliceStable(keys, func(i, j int) bool {
+			orderI, orderJ := order(keys[i]), order(keys[j])
+			if orderI != orderJ {
+				return orderI < orderJ
+			}
+			return keys[i] < keys[j]
+		})
+		for _, key := range keys {
+			node.Set(key, convertTOMLValue(v[key], append(append([]string{}, path...), key), positions))
+		}
+	case []any:
+		node = NewArrayNode(make([]*DataNode, 0, len(v)))
+		for i, item := range v {
+			node.Items = append(node.Items, convertTOMLValue(item, append(append([]string{}, path...), strconv.Itoa(i)), positions))
+		}
+	case string:
+		node = NewStringNode(v)
+	case bool:
+		node = NewBoolNode(v)
+	case int64:
+		node = &DataNode{Kind: NodeNumber, Value: strconv.FormatInt(v, 10)}
+	case float64:
+		node = &DataNode{Kind: NodeNumber, Value: strconv.FormatFloat(v, 'g', -1, 64)}
+	case nil:
+		node = &DataNode{Kind: NodeNull}
+	default:
+		node = NewStringNode(fmt.Sprintf("%v", v))
+	}
+	if position, exists := positions[strings.Join(path, "\x00")]; exists {
+		node.Line, node.Column = position.line, position.column
+	}
+	return node
+}
+
+var tomlBareKeyPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// encodeTOMLNode записывает дерево в виде таблиц: простые значения идут перед вложенными таблицами.
+func encodeTOMLNode(node *DataNode) ([]byte, error) {
+	if node.Kind != NodeObject {
+		return nil, NewAppError(Err, "encode_error", map[string]any{
+			"error": "the root of a TOML document must be a table",
+		})
+	}
+	var buf bytes.Buffer
+	writeTOMLTable(&buf, []string{}, node)
+	return bytes.TrimLeft(buf.Bytes(), "\n"), nil
+}
+
+func writeTOMLTable(buf *bytes.Buffer, path []string, node *DataNode) {
+	simpleKeys := make([]string, 0, len(node.Keys))
+	tableKeys := make([]string, 0, len(node.Keys))
+	for _, key := range node.Keys {
+		switch node.Fields[key].Kind {
+		case NodeObject:
+			tableKeys = append(tableKeys, key)
+		case NodeNull:
+		default:
+			simpleKeys = append(simpleKeys, key)
+		}
+	}
+	if len(path) > 0 && (len(simpleKeys) > 0 || len(node.Keys) == 0) {
+		headerKeys := make([]string, 0, len(path))
+		for _, key := range path {
+			headerKeys = append(headerKeys, tomlKey(key))
+		}
+		buf.WriteString("\n[" + strings.Join(headerKeys, ".") + "]\n")
+	}
+	for _, key := range simpleKeys {
+		buf.WriteString(tomlKey(key) + " = " + tomlValue(node.Fields[key]) + "\n")
+	}
+	for _, key := range tableKeys {
+		writeTOMLTable(buf, append(append([]string{}, path...), key), node.Fields[key])
+	}
+}
+
+func tomlKey(key string) string {
+	if tomlBareKeyPattern.MatchString(key) {
+		return key
+	}
+	return tomlString(key)
+}
+
+func tomlValue(node *DataNode) string {
+	switch node.Kind {
+	case NodeObject:
+		parts := make([]string, 0, len(node.Keys))
+		for _, key := range node.Keys {
+			if node.Fields[key].Kind != NodeNull {
+				parts = append(parts, tomlKey(key)+" = "+tomlValue(node.Fields[key]))
+			}
+		}
+		if len(parts) == 0 {
+			return "{}"
+		}
+		return "{ " + strings.Join(parts, ", ") + " }"
+	case NodeArray:
+		parts := make([]string, 0, len(node.Items))
+		for _, item := range node.Items {
+			parts = append(parts, tomlValue(item))
+		}
+		return "[" + strings.Join(parts, ", ") + "]"
+	case NodeString:
+		return tomlString(node.Value)
+	default:
+		return node.Value
+	}
+}
+
+func tomlString(s string) string {
+	var buf strings.Builder
+	buf.WriteString(`"`)
+	for _, r := range s {
+		switch r {
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&buf, `\u%04X`, r)
+				continue
+			}
+			buf.WriteRune(r)
+		}
+	}
+	buf.WriteString(`"`)
+	return buf.String()
+}
@@ -0,0 +1,144 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var yamlLinePattern = regexp.MustCompile(`line (\d+)`)
+
+func parseYAMLNode(data []byte) (*DataNode, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		if match := yamlLinePattern.FindStringSubmatch(err.Error()); match != nil {
+			line, _ := strconv.Atoi(match[1])
+			return nil, positionError(line, 0, err)
+		}
+		return nil, NewAppError(Err, "decode_error", map[string]any{
+			"error": err,
+		})
+	}
+	if doc.Kind == 0 {
+		return NewObjectNode(), nil
+	}
+	return convertYAMLNode(&doc)
+}
+
+func convertYAMLNode(n *yaml.Node) (*DataNode, error) {
+	var node *DataNode
+	switch n.Kind {
+	case yaml.DocumentNode:
+		if len(n.Content) == 0 {
+			return NewObjectNode(), nil
+		}
+		return convertYAMLNode(n.Content[0])
+	case yaml.AliasNode:
+		return convertYAMLNode(n.Alias)
+	case yaml.MappingNode:
+		node = NewObjectNode()
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key, value := n.Content[i], n.Content[i+1]
+			child, err := convertYAMLNode(value)
+			if err != nil {
+				return nil, err
+			}
+			// позиция ключа понятнее пользователю, чем позиция начала вложенного блока
+			child.Line, child.Column = key.Line, key.Column
+			node.Set(key.Value, child)
+		}
+	case yaml.SequenceNode:
+		node = NewArrayNode(make([]*DataNode, 0, len(n.Content)))
+		for _, item := range n.Content {
+			child, err := convertYAMLNode(item)
+			if err != nil {
+				return nil, err
+			}
+			node.Items = append(node.Items, child)
+		}
+	default:
+		scalar, err := convertYAMLScalar(n)
+		if err != nil {
+			return nil, err
+		}
+		node = scalar
+	}
+	node.Line, node.Column = n.Line, n.Column
+	return node, nil
+}
+
+func convertYAMLScalar(n *yaml.Node) (*DataNode, error) {
+	switch n.ShortTag() {
+	case "!!null":
+		return &DataNode{Kind: NodeNull}, nil
+	case "!!bool":
+		var value bool
+		if err := n.Decode(&value); err != nil {
+			return nil, positionError(n.Line, n.Column, err)
+		}
+		return NewBoolNode(value), nil
+	case "!!int", "!!float":
+		var value any
+		if err := n.Decode(&value); err != nil {
+			return nil, positionError(n.Line, n.Column, err)
+		}
+		return &DataNode{Kind: NodeNumber, Value: fmt.Sprintf("%v", value)}, nil
+	default:
+		return NewStringNode(n.Value), nil
+	}
+}
+
+func encodeYAMLNode(node *DataNode) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(toYAMLNode(node)); err != nil {
+		return nil, NewAppError(Err, "encode_error", map[string]any{
+			"error": err,
+		})
+	}
+	if err := enc.Close(); err != nil {
+		return nil, NewAppError(Err, "encode_error", map[string]any{
+			"error": err,
+		})
+	}
+	return buf.Bytes(), nil
+}
+
+func toYAMLNode(node *DataNode) *yaml.Node {
+	switch node.Kind {
+	case NodeObject:
+		n := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		for _, key := range node.Keys {
+			n.Content = append(n.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, toYAMLNode(node.Fields[key]))
+		}
+		return n
+	case NodeArray:
+		n := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		if node.isFlat() {
+			n.Style = yaml.FlowStyle
+		}
+		for _, item := range node.Items {
+			n.Content = append(n.Content, toYAMLNode(item))
+		}
+		return n
+	case NodeString:
+		n := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: node.Value}
+		if strings.Contains(node.Value, "\n") {
+			n.Style = yaml.LiteralStyle
+		}
+		return n
+	case NodeNumber:
+		if strings.ContainsAny(node.Value, ".eE") {
+			return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!float", Value: node.Value}
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: node.Value}
+	case NodeBool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: node.Value}
+	default:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+	}
+}
@@ -0,0 +1,460 @@
+package core
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// EngineHooks позволяют фронтендам реагировать на события цикла, не копируя сам цикл.
+type EngineHooks struct {
+	OnStart       func(e *Engine)
+	OnStateChange func(e *Engine, from, to State)
+	OnError       func(e *Engine, err error)
+	OnShutdown    func(e *Engine)
+	// OnDisplay вызывается после показа состояния, перед ожиданием ввода
+	OnDisplay func(e *Engine, state State)
+}
+
+// Engine управляет циклом конечного автомата: показывает состояние, читает ввод и применяет переходы.
+type Engine struct {
+	App        *AppContext
+	UI         *UiContext
+	StartState State
+	Hooks      EngineHooks
+	// History хранит введённые строки между запусками; без неё консоль помнит ввод только до выхода
+	History  *InputHistory
+	done     chan struct{}
+	stopOnce sync.Once
+	// forceExit выставляется сигналом, после которого выход не требует подтверждения
+	forceExit atomic.Bool
+	// interrupted означает, что предыдущий ввод был прерван, и следующее прерывание завершит работу
+	interrupted bool
+	// последние строки ввода для отчёта о сбое
+	inputs []string
+	// ввод читается в отдельной горутине, чтобы цикл мог одновременно ждать таймеры и события;
+	// stopRead отменяет незавершённое чтение и равен nil, пока ничего не читается
+	reads    chan readResult
+	stopRead context.CancelFunc
+	// skipDisplay не даёт заново показывать состояние, если событие или клавиша его не изменили
+	skipDisplay bool
+	// lineMode просит следующий раз читать строку, даже если состояние управляется клавишами;
+	// lineText — уже набранное начало этой строки
+	lineMode bool
+	lineText string
+	// deadline — срок ввода для состояния с InputDeadline, отсчитывается от его показа
+	deadline time.Time
+	// location — начало строки ввода, показывающее, где находится игрок; обновляется при каждом показе состояния
+	location string
+	// historyScope — область истории, в которую попадёт строка текущего чтения
+	historyScope string
+}
+
+type readResult struct {
+	input string
+	key   Key
+	isKey bool
+	err   error
+}
+
+func NewEngine(app *AppContext, ui *UiContext, startState State) *Engine {
+	if app.Scheduler == nil {
+		app.Scheduler = NewScheduler()
+	}
+	return &Engine{
+		App:        app,
+		UI:         ui,
+		StartState: startState,
+		done:       make(chan struct{}),
+		reads:      make(chan readResult, 1),
+	}
+}
+
+// Shutdown просит движок завершить работу после текущего шага; безопасно вызывать из других горутин.
+func (e *Engine) Shutdown() {
+	e.stopOnce.Do(func() {
+		close(e.done)
+	})
+}
+
+// Interrupt прерывает ожидание ввода так же, как Ctrl+C; при force выход происходит без подтверждения.
+func (e *Engine) Interrupt(force bool) {
+	if force {
+		e.forceExit.Store(true)
+	}
+	e.UI.Console.CancelRead()
+}
+
+// ListenForSignals направляет SIGINT и SIGTERM в движок; возвращённая функция прекращает прослушивание.
+func (e *Engine) ListenForSignals() (stop func()) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case sig := <-signals:
+				e.Interrupt(sig == syscall.SIGTERM)
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
+
+func (e *Engine) isStopped(ctx context.Context) bool {
+	select {
+	case <-e.done:
+		return true
+	case <-ctx.Done():
+		return true
+	default:
+		return false
+	}
+}
+
+// Run выполняет цикл до перехода Exit, вызова Shutdown или отмены контекста.
+func (e *Engine) Run(ctx context.Context) error {
+	defer e.shutdown()
+	currentState, err := e.App.GoToState(e.StartState, e.UI)
+	e.reportError(err)
+	if currentState == nil {
+		return NewAppError(ErrStateStack, "state_stack_empty", nil)
+	}
+	if e.Hooks.OnStart != nil {
+		e.Hooks.OnStart(e)
+	}
+	for !e.isStopped(ctx) {
+		nextState, exit := e.iterate(ctx, currentState)
+		if exit {
+			e.Shutdown()
+			break
+		}
+		if nextState == nil {
+			return NewAppError(ErrStateStack, "state_stack_empty", nil)
+		}
+		currentState = nextState
+	}
+	return ctx.Err()
+}
+
+// iterate выполняет один шаг цикла. Паника в обработчике не роняет хаб:
+// сохраняется отчёт о сбое, а игрок возвращается в главное меню.
+func (e *Engine) iterate(ctx context.Context, currentState State) (nextState State, exit bool) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			nextState, exit = e.recoverCrash(recovered, debug.Stack()), false
+		}
+	}()
+	if !e.skipDisplay {
+		currentState.Display(e.App, e.UI)
+		e.deadline = e.inputDeadline(currentState)
+		e.updateLocation()
+		if e.Hooks.OnDisplay != nil {
+			e.Hooks.OnDisplay(e, currentState)
+		}
+	}
+	e.skipDisplay = false
+	transition, err := e.step(ctx, currentState)
+	e.reportError(err)
+	if appErr, ok := err.(*AppError); ok && appErr.Code == ErrStateStack {
+		e.reportError(e.unwind())
+		transition = Push(e.StartState)
+	}
+	if transition.Kind == TransitionExit {
+		return currentState, true
+	}
+	nextState, err = e.App.Apply(transition, e.UI)
+	e.reportError(err)
+	if transition.Kind != TransitionStay || nextState != currentState {
+		e.cancelRead()
+	}
+	if nextState != nil && nextState != currentState && e.Hooks.OnStateChange != nil {
+		e.Hooks.OnStateChange(e, currentState, nextState)
+	}
+	return nextState, false
+}
+
+// unwind закрывает все состояния так же, как выход из программы: вызываются их OnExit, а игра выгружается,
+// даже если состояния её загрузки в стеке уже нет.
+func (e *Engine) unwind() error {
+	return JoinErrors(e.App.Unwind(e.UI), e.App.LeaveGame(e.UI))
+}
+
+func (e *Engine) recoverCrash(recovered any, stack []byte) State {
+	if err := e.UI.Console.RestoreTerminal(); err != nil {
+		e.UI.Logger.Warn("failed to restore the terminal after a crash", "error", err)
+	}
+	report := NewCrashReport(e.App, e.UI, recovered, stack, e.inputs)
+	if filePath, err := WriteCrashReport(e.App.Config, report); err != nil {
+		e.reportError(NewAppError(ErrInternal, "crash_report_error", map[string]any{
+			"error": err,
+		}))
+	} else {
+		e.reportError(NewAppError(ErrInternal, "crash_occurred", map[string]any{
+			"file": filePath,
+		}))
+	}
+	e.App.Scheduler.CancelAll()
+	e.cancelRead()
+	e.unwindAfterCrash()
+	state, err := e.App.GoToState(e.StartState, e.UI)
+	e.reportError(err)
+	return state
+}
+
+// unwindAfterCrash закрывает состояния обычным путём; если после сбоя паникует и чей-то OnExit,
+// оставшиеся состояния отбрасываются без хуков, а игра выгружается без сохранения.
+func (e *Engine) unwindAfterCrash() {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			e.UI.Logger.Warn("state exit hook panicked after a crash", "panic", recovered)
+			e.App.StateStack.Clear()
+			e.App.detachGame(e.UI)
+		}
+	}()
+	e.reportError(e.unwind())
+}
+
+// updateLocation передаёт консоли, где находится игрок; консоли без строки приглашения, как полноэкранная, показывают это по-своему.
+func (e *Engine) updateLocation() {
+	prompter, ok := e.UI.Console.(LocationPrompter)
+	if !ok {
+		return
+	}
+	e.location = e.UI.LocationPrompt(e.App)
+	prompter.SetLocation(e.location)
+}
+
+// useHistory выбирает историю для чтения строки: строку команды, открытую из состояния с клавишами, листает история команд,
+// а ввод для самих состояний — история игры или меню хаба, чтобы стрелка вверх в меню не возвращала догадки из игры.
+func (e *Engine) useHistory(commandLine bool) {
+	switch {
+	case commandLine:
+		e.historyScope = HistoryCommands
+	case e.App.Game != nil:
+		e.historyScope = GameHistory(e.App.Game.GetId())
+	default:
+		e.historyScope = HistoryHub
+	}
+	console, ok := e.UI.Console.(HistoryConsole)
+	if !ok || e.History == nil {
+		return
+	}
+	lines, err := e.History.Lines(e.historyScope)
+	e.UI.logError(err)
+	console.SetHistory(e.historyScope, lines)
+}
+
+// remember сохраняет строку в истории, из которой она читалась; команды, введённые в игре, попадают ещё и в историю команд.
+func (e *Engine) remember(input string) {
+	if e.History == nil {
+		return
+	}
+	e.UI.logError(e.History.Add(e.historyScope, input))
+	if e.historyScope == HistoryCommands {
+		return
+	}
+	if cmd, _ := e.UI.CommandRegistry.ParseInput(input, e.App); cmd != nil {
+		e.UI.logError(e.History.Add(HistoryCommands, input))
+	}
+}
+
+func (e *Engine) recordInput(input string) {
+	e.inputs = append(e.inputs, input)
+	if len(e.inputs) > CrashReportInputs {
+		e.inputs = e.inputs[len(e.inputs)-CrashReportInputs:]
+	}
+}
+
+// step ждёт ввода, если он нужен состоянию, и одновременно таймеров и событий из других горутин.
+// Ввод передаётся командам или самому состоянию, события — состоянию через HandleEvent.
+func (e *Engine) step(ctx context.Context, state State) (Transition, error) {
+	if !state.RequiresInput() {
+		return e.UI.HandleInput("", e.App)
+	}
+	if e.stopRead == nil {
+		e.startRead(ctx, state)
+	}
+	select {
+	case result := <-e.reads:
+		e.stopRead()
+		e.stopRead = nil
+		return e.handleRead(state, result)
+	case event := <-e.App.Scheduler.Events():
+		return e.dispatchEvent(state, event)
+	case <-e.done:
+		return Exit(), nil
+	case <-ctx.Done():
+		return Exit(), nil
+	}
+}
+
+func (e *Engine) inputDeadline(state State) time.Time {
+	if timed, ok := state.(InputDeadline); ok {
+		if timeout := timed.InputTimeout(e.App, e.UI); timeout > 0 {
+			return time.Now().Add(timeout)
+		}
+	}
+	return time.Time{}
+}
+
+// startRead читает в отдельной горутине клавишу, если состояние управляется клавишами и ввод идёт из терминала, иначе строку.
+func (e *Engine) startRead(ctx context.Context, state State) {
+	keys, keyMode := e.UI.KeyReader()
+	handler, isKeyHandler := state.(KeyHandler)
+	lineMode, text := e.lineMode, e.lineText
+	e.lineMode, e.lineText = false, ""
+	prompt := ""
+	if keyMode && isKeyHandler && !lineMode {
+		prompt = e.location + handler.KeyPrompt(e.App, e.UI)
+	}
+	if !keyMode || !isKeyHandler || lineMode {
+		e.useHistory(keyMode && isKeyHandler)
+	}
+	var readCtx context.Context
+	if e.deadline.IsZero() {
+		readCtx, e.stopRead = context.WithCancel(ctx)
+	} else {
+		readCtx, e.stopRead = context.WithDeadline(ctx, e.deadline)
+	}
+	go func() {
+		var result readResult
+		switch {
+		case keyMode && text != "":
+			result.input, result.err = keys.ReadWithText(readCtx, text)
+		case keyMode && isKeyHandler && !lineMode:
+			result.key, result.err = keys.ReadKey(readCtx, prompt)
+			result.isKey = true
+		default:
+			result.input, result.err = e.UI.Console.ReadContext(readCtx)
+		}
+		e.reads <- result
+	}()
+}
+
+// cancelRead отменяет чтение, начатое для прежнего состояния, и отбрасывает его результат:
+// новое состояние начнёт своё чтение со своим приглашением, сроком и режимом клавиш или строки.
+func (e *Engine) cancelRead() {
+	if e.stopRead == nil {
+		return
+	}
+	e.stopRead()
+	e.stopRead = nil
+	<-e.reads
+	e.lineMode, e.lineText = false, ""
+}
+
+func (e *Engine) handleRead(state State, result readResult) (Transition, error) {
+	if appErr, ok := result.err.(*AppError); ok && appErr.Code == ErrInterrupt {
+		return e.interrupt(), nil
+	}
+	if appErr, ok := result.err.(*AppError); ok && appErr.Code == ErrTimeout {
+		return e.handleTimeout(state)
+	}
+	e.reportError(result.err)
+	if appErr, ok := result.err.(*AppError); ok && appErr.Code == ErrEOF {
+		// ввод закончился: завершаем работу так же, как по команде выхода
+		return Push(&ExitState{}), nil
+	}
+	e.interrupted = false
+	if result.isKey {
+		e.recordInput(result.key.String())
+		return e.handleKey(state, result.key)
+	}
+	e.recordInput(result.input)
+	e.remember(result.input)
+	return e.UI.HandleInput(result.input, e.App)
+}
+
+// handleTimeout передаёт истёкший срок состоянию; состояние без InputDeadline срока не задаёт, и тогда просто ждём дальше.
+func (e *Engine) handleTimeout(state State) (Transition, error) {
+	e.recordInput("<timeout>")
+	if timed, ok := state.(InputDeadline); ok {
+		return timed.HandleTimeout(e.App, e.UI)
+	}
+	e.skipDisplay = true
+	return Stay(), nil
+}
+
+func (e *Engine) handleKey(state State, key Key) (Transition, error) {
+	if handler, ok := state.(KeyHandler); ok {
+		transition, result, err := handler.HandleKey(e.App, e.UI, key)
+		switch result {
+		case KeyHandled:
+			return transition, err
+		case KeyConsumed:
+			// новую строку ввода покажет следующее чтение
+			e.skipDisplay = err == nil && transition.Kind == TransitionStay
+			return transition, err
+		}
+	}
+	// остальные клавиши переключают на ввод строки, чтобы по-прежнему можно было набрать команду
+	e.lineMode = true
+	if key.Code == KeyRune {
+		e.lineText = string(key.Rune)
+	}
+	e.skipDisplay = true
+	return Stay(), nil
+}
+
+// dispatchEvent публикует асинхронное событие в шине и передаёт его текущему состоянию.
+func (e *Engine) dispatchEvent(state State, event Event) (Transition, error) {
+	if timer, ok := event.(TimerFired); ok && !e.App.Scheduler.claim(timer) {
+		// таймер отменили, пока событие шло в цикл
+		e.skipDisplay = true
+		return Stay(), nil
+	}
+	e.App.Events.Publish(event)
+	handler, ok := state.(EventHandler)
+	if !ok {
+		e.skipDisplay = true
+		return Stay(), nil
+	}
+	return handler.HandleEvent(e.App, e.UI, event)
+}
+
+// interrupt обрабатывает Ctrl+C и сигналы: первое прерывание просит подтвердить выход,
+// а повторное подряд или SIGTERM завершают работу сразу.
+func (e *Engine) interrupt() Transition {
+	if e.forceExit.Load() || e.interrupted {
+		return Push(&ExitState{})
+	}
+	e.interrupted = true
+	e.UI.DisplayText("\r\n")
+	return Push(NewConfirmationDialog(&ExitState{}, "quit_confirm"))
+}
+
+// shutdown закрывает состояния, сохраняет настройки и текущую игру и сбрасывает логи, пока консоль ещё открыта.
+func (e *Engine) shutdown() {
+	e.App.Scheduler.Stop()
+	// состояния закрываются раньше автосохранения, чтобы успеть сохранить свои данные
+	e.reportError(e.App.Unwind(e.UI))
+	e.reportError(Autosave(e.App, e.UI))
+	if e.Hooks.OnShutdown != nil {
+		e.Hooks.OnShutdown(e)
+	}
+	if err := e.UI.Logger.Flush(); err != nil {
+		e.UI.DisplayError(err)
+	}
+}
+
+func (e *Engine) reportError(err error) {
+	if err == nil {
+		return
+	}
+	if e.Hooks.OnError != nil {
+		e.Hooks.OnError(e, err)
+		return
+	}
+	e.UI.DisplayError(err)
+}
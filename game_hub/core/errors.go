@@ -15,6 +15,8 @@ const (
 	ErrOutOfRange   ErrorCode = "OUT_OF_RANGE"
 	ErrInvalidRange ErrorCode = "INVALID_RANGE"
 	ErrEOF          ErrorCode = "END_OF_INPUT"
+	ErrInterrupt    ErrorCode = "INTERRUPT"
+	ErrTimeout      ErrorCode = "TIMEOUT"
 	ErrStateStack             = "STATE_STACK_ERROR"
 	ErrLocalization           = "LOCALIZATION_ERROR"
 	ErrCommand                = "COMMAND_ERROR"
@@ -58,6 +60,24 @@ func NewAppErrors(errs []error) *AppErrors {
 	return appErrors
 }
 
+// JoinErrors пропускает пустые ошибки и объединяет остальные; одна ошибка возвращается как есть.
+func JoinErrors(errs ...error) error {
+	appErrors := NewAppErrors(nil)
+	for _, err := range errs {
+		if err != nil {
+			appErrors.Add(err)
+		}
+	}
+	switch len(appErrors.Errors) {
+	case 0:
+		return nil
+	case 1:
+		return appErrors.Errors[0]
+	default:
+		return appErrors
+	}
+}
+
 func (e *AppErrors) Add(err error) {
 	e.Errors = append(e.Errors, err)
 }
@@ -0,0 +1,164 @@
+package core
+
+import (
+	"reflect"
+	"sync"
+)
+
+// Event — событие хаба или игры. Подписчики различают события по их типу.
+type Event interface {
+	EventName() string
+}
+
+type GameOutcome string
+
+const (
+	OutcomeWin  GameOutcome = "win"
+	OutcomeLoss GameOutcome = "loss"
+	OutcomeDraw GameOutcome = "draw"
+)
+
+// GameStarted публикуется, когда начинается новая партия.
+type GameStarted struct {
+	GameId string
+}
+
+func (e GameStarted) EventName() string {
+	return "game_started"
+}
+
+// RoundPlayed публикуется после каждого раунда в играх, состоящих из раундов.
+type RoundPlayed struct {
+	GameId  string
+	Round   int
+	Outcome GameOutcome
+}
+
+func (e RoundPlayed) EventName() string {
+	return "round_played"
+}
+
+// GuessMade публикуется после каждой попытки угадать.
+type GuessMade struct {
+	GameId       string
+	Guess        int
+	Correct      bool
+	AttemptsLeft int
+}
+
+func (e GuessMade) EventName() string {
+	return "guess_made"
+}
+
+// GameFinished публикуется, когда партия закончилась.
+type GameFinished struct {
+	GameId  string
+	Outcome GameOutcome
+}
+
+func (e GameFinished) EventName() string {
+	return "game_finished"
+}
+
+// LanguageChanged публикуется при смене языка в меню и при запуске, если сохранённый язык отличается от языка по умолчанию.
+// Подписчики, появившиеся позже, узнают текущий язык из LocalizationManager.CurrentLang.
+type LanguageChanged struct {
+	From string
+	To   string
+}
+
+func (e LanguageChanged) EventName() string {
+	return "language_changed"
+}
+
+// StateEntered публикуется, когда состояние открыто переходом Push или Replace.
+type StateEntered struct {
+	State State
+}
+
+func (e StateEntered) EventName() string {
+	return "state_entered"
+}
+
+func (e StateEntered) String() string {
+	return "{State:" + e.State.Id() + "}"
+}
+
+type subscription struct {
+	id      int
+	handler func(Event)
+}
+
+// EventBus доставляет события подписчикам синхронно, в порядке подписки.
+type EventBus struct {
+	mu       sync.RWMutex
+	handlers map[reflect.Type][]subscription
+	// подписчики на все события, например журнал
+	all    []subscription
+	nextId int
+}
+
+func NewEventBus() *EventBus {
+	return &EventBus{
+		handlers: make(map[reflect.Type][]subscription),
+	}
+}
+
+// Subscribe подписывает обработчик на события типа E и возвращает функцию отписки.
+func Subscribe[E Event](bus *EventBus, handler func(E)) (unsubscribe func()) {
+	var zero E
+	eventType := reflect.TypeOf(zero)
+	bus.mu.Lock()
+	defer bus.mu.Unlock()
+	bus.nextId++
+	id := bus.nextId
+	bus.handlers[eventType] = append(bus.handlers[eventType], subscription{
+		id: id,
+		handler: func(event Event) {
+			handler(event.(E))
+		},
+	})
+	return func() {
+		bus.mu.Lock()
+		defer bus.mu.Unlock()
+		bus.handlers[eventType] = removeSubscription(bus.handlers[eventType], id)
+	}
+}
+
+// SubscribeAll подписывает обработчик на события любого типа.
+func (b *EventBus) SubscribeAll(handler func(Event)) (unsubscribe func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.nextId++
+	id := b.nextId
+	b.all = append(b.all, subscription{id: id, handler: handler})
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.all = removeSubscription(b.all, id)
+	}
+}
+
+// Publish вызывает подписчиков события. Пустая шина ничего не делает, поэтому игры могут публиковать события без проверок.
+func (b *EventBus) Publish(event Event) {
+	if b == nil {
+		return
+	}
+	b.mu.RLock()
+	handlers := append([]subscription{}, b.handlers[reflect.TypeOf(event)]...)
+	handlers = append(handlers, b.all...)
+	b.mu.RUnlock()
+	// обработчики вызываются без блокировки, чтобы они могли подписываться и публиковать сами
+	for _, s := range handlers {
+		s.handler(event)
+	}
+}
+
+func removeSubscription(subscriptions []subscription, id int) []subscription {
+	for i, s := range subscriptions {
+		if s.id == id {
+			return append(subscriptions[:i:i], subscriptions[i+1:]...)
+		}
+	}
+	return subscriptions
+}
@@ -6,3 +6,25 @@ type GameInterface interface {
 	GetId() string
 	GetStartState() State
 }
+
+// GameWithCommands реализуют игры, у которых есть команды, доступные во всех их состояниях.
+type GameWithCommands interface {
+	GameInterface
+	GetCommands() []Command
+}
+
+// GameWithStatus реализуют игры, которые могут кратко описать текущую партию, например счёт или оставшиеся попытки.
+type GameWithStatus interface {
+	GameInterface
+	// Status возвращает локализованную строку состояния; пустая строка — показывать нечего
+	Status(ui *UiContext) string
+}
+
+// gameCommands собирает слой команд игры: выход в её главное меню, сохранение и собственные команды игры.
+func gameCommands(game GameInterface) []Command {
+	cmds := []Command{&ExitCommand{}, &SaveCommand{}}
+	if withCommands, ok := game.(GameWithCommands); ok {
+		cmds = append(cmds, withCommands.GetCommands()...)
+	}
+	return cmds
+}
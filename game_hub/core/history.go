@@ -0,0 +1,145 @@
+package core
+
+import (
+	"bufio"
+	"game_hub/config"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Области истории ввода: стрелка вверх листает только то, что вводилось в том же месте.
+const (
+	// HistoryHub — ввод в меню хаба, когда они читают строки, например в режиме доступности
+	HistoryHub = "hub"
+	// HistoryCommands — команды; их листает строка команды, открытая из меню клавишей Escape или набором текста
+	HistoryCommands = "commands"
+)
+
+// GameHistory возвращает область истории игры: догадки из одной игры не попадаются в другой.
+func GameHistory(gameId string) string {
+	return "game_" + gameId
+}
+
+// InputHistory хранит истории ввода по областям в каталоге состояния пользователя, чтобы они переживали перезапуск.
+// Файл области дописывается по строке и переписывается целиком, только когда вырастет вдвое больше лимита.
+type InputHistory struct {
+	cfg   *config.Config
+	limit int
+	lines map[string][]string
+	// written — сколько строк сейчас в файле области, вместе с уже вытесненными из памяти
+	written map[string]int
+}
+
+// NewInputHistory создаёт историю, которая хранит не больше limit строк в каждой области; при limit == 0 история не ведётся.
+func NewInputHistory(cfg *config.Config, limit int) *InputHistory {
+	return &InputHistory{
+		cfg:     cfg,
+		limit:   limit,
+		lines:   make(map[string][]string),
+		written: make(map[string]int),
+	}
+}
+
+// Lines возвращает историю области от старых строк к новым, при первом обращении читая её из файла.
+func (h *InputHistory) Lines(scope string) ([]string, error) {
+	if lines, loaded := h.lines[scope]; loaded || h.limit == 0 {
+		return lines, nil
+	}
+	lines, err := h.load(scope)
+	if err != nil {
+		// непрочитанный файл не мешает запоминать новый ввод: при следующей записи он будет переписан
+		h.written[scope] = 2 * h.limit
+	}
+	h.lines[scope] = lines
+	return lines, err
+}
+
+// Add запоминает введённую строку; пустые строки и повтор предыдущей не запоминаются.
+func (h *InputHistory) Add(scope, line string) error {
+	line = strings.TrimSpace(line)
+	if h.limit == 0 || line == "" {
+		return nil
+	}
+	// ошибка чтения уже учтена в Lines
+	lines, _ := h.Lines(scope)
+	if len(lines) > 0 && lines[len(lines)-1] == line {
+		return nil
+	}
+	lines = append(lines, line)
+	if len(lines) > h.limit {
+		lines = lines[len(lines)-h.limit:]
+	}
+	h.lines[scope] = lines
+	if h.written[scope]+1 > 2*h.limit {
+		return h.rewrite(scope)
+	}
+	return h.append(scope, line)
+}
+
+func (h *InputHistory) load(scope string) ([]string, error) {
+	file, err := os.Open(h.cfg.Paths.HistoryPath(scope))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, h.readError(scope, err)
+	}
+	defer file.Close()
+	lines := make([]string, 0)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		h.written[scope]++
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) > h.limit {
+		lines = lines[len(lines)-h.limit:]
+	}
+	if err := scanner.Err(); err != nil {
+		return lines, h.readError(scope, err)
+	}
+	return lines, nil
+}
+
+func (h *InputHistory) append(scope, line string) error {
+	filePath := h.cfg.Paths.HistoryPath(scope)
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return h.writeError(scope, err)
+	}
+	file, err := os.OpenFile(filePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return h.writeError(scope, err)
+	}
+	_, err = file.WriteString(line + "\n")
+	if err := JoinErrors(err, file.Close()); err != nil {
+		return h.writeError(scope, err)
+	}
+	h.written[scope]++
+	return nil
+}
+
+// rewrite оставляет в файле только строки, которые помещаются в лимит.
+func (h *InputHistory) rewrite(scope string) error {
+	lines := h.lines[scope]
+	if err := WriteFile(h.cfg.Paths.HistoryPath(scope), []byte(strings.Join(lines, "\n")+"\n")); err != nil {
+		return err
+	}
+	h.written[scope] = len(lines)
+	return nil
+}
+
+func (h *InputHistory) readError(scope string, err error) error {
+	return NewAppError(Err, "history_read_error", map[string]any{
+		"file":  h.cfg.Paths.HistoryPath(scope),
+		"error": err,
+	})
+}
+
+func (h *InputHistory) writeError(scope string, err error) error {
+	return NewAppError(Err, "file_write_error", map[string]any{
+		"file":  h.cfg.Paths.HistoryPath(scope),
+		"error": err,
+	})
+}
@@ -3,6 +3,7 @@ package core
 import (
 	"game_hub/config"
 	"log"
+	"reflect"
 	"sort"
 )
 
@@ -12,22 +13,24 @@ type Language struct {
 }
 
 type LocalizationMetadata struct {
+	SchemaVersion      int      `json:"schema_version"`
 	SupportedLanguages []string `json:"supported_languages" validate:"required,min=1"`
 }
 
+type SchemaMetadata struct {
+	SchemaVersion int `json:"schema_version"`
+}
+
 func NewLocalizationMetadata() LocalizationMetadata {
 	return LocalizationMetadata{
 		SupportedLanguages: make([]string, 0, 10),
 	}
 }
 
-type LocalizationData struct {
-	Meta         LocalizationMetadata `json:"meta" validate:"required"`
-	Translations map[string]any       `json:"translations" validate:"required"`
-}
-
+// LangDictData — словарь языков: каждый язык назван на нём самом, чтобы игрок узнал свой язык в меню выбора.
 type LangDictData struct {
-	Languages map[string]string `json:"languages" validate:"required"`
+	Meta      SchemaMetadata    `json:"meta"`
+	Languages map[string]string `json:"languages" validate:"required,dive,required"`
 }
 
 type LocalizationManager struct {
@@ -49,7 +52,7 @@ func NewLocalizationManager(cfg *config.Config) (*LocalizationManager, error) {
 	defaultLang := cfg.Language.DefaultLanguage
 	dictFilePath := cfg.Paths.CoreLanguagesPath()
 	var rawData LangDictData
-	if err := LoadData(dictFilePath, &rawData); err != nil {
+	if err := lm.loadData(dictFilePath, &rawData); err != nil {
 		return nil, NewAppError(ErrLocalization, "load_lang_dict_error", map[string]any{
 			"file":  dictFilePath,
 			"error": err,
@@ -74,19 +77,25 @@ func (lm *LocalizationManager) SetLogger(logger Logger) {
 	lm.logger = logger
 }
 
-func (lm *LocalizationManager) loadLocalizationData(filePath string, target any) error {
-	data, err := ReadFile(filePath)
+// loadLocalizationData возвращает и разобранное дерево файла, чтобы загрузчики могли указать позиции найденных проблем.
+func (lm *LocalizationManager) loadLocalizationData(filePath string, target any) (*DataNode, error) {
+	node, err := lm.loadDataNode(filePath)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	var rawData LocalizationData
-	if err := DecodeData(data, &rawData); err != nil {
-		return NewAppError(ErrLocalization, "file_parse_error", map[string]any{
-			"file":  filePath,
-			"error": err,
-		})
+	// до декодирования проверяются только типы метаданных: файл целиком декодируется и проверяется один раз, уже в target
+	rawMeta := NewLocalizationMetadata()
+	if metaNode := node.Get("meta"); metaNode != nil {
+		diagnostics := NewDataDiagnostics(filePath, node)
+		diagnostics.checkNodeType(metaNode, reflect.TypeOf(rawMeta), []string{"meta"})
+		if err := diagnostics.Err(); err != nil {
+			return nil, err
+		}
+		if langs := metaNode.Get("supported_languages"); langs != nil {
+			rawMeta.SupportedLanguages = langs.Strings()
+		}
 	}
-	validMeta, logErrors, err := lm.validateMetadata(rawData.Meta)
+	validMeta, logErrors, err := lm.validateMetadata(rawMeta)
 	if logErrors != nil {
 		lm.logError(NewAppError(ErrLocalization, "invalid_localization_metadata", map[string]any{
 			"file":  filePath,
@@ -94,26 +103,33 @@ func (lm *LocalizationManager) loadLocalizationData(filePath string, target any)
 		}))
 	}
 	if err != nil {
-		return NewAppError(ErrLocalization, "invalid_localization_metadata", map[string]any{
+		return nil, NewAppError(ErrLocalization, "invalid_localization_metadata", map[string]any{
 			"file":  filePath,
 			"error": err,
 		})
 	}
-	rawData.Meta = validMeta
-	updatedData, err := EncodeData(rawData)
-	if err != nil {
-		return NewAppError(ErrLocalization, "file_parse_error", map[string]any{
-			"file":  filePath,
-			"error": err,
-		})
+	validLangs := make([]*DataNode, 0, len(validMeta.SupportedLanguages))
+	for _, lang := range validMeta.SupportedLanguages {
+		validLangs = append(validLangs, NewStringNode(lang))
 	}
-	if err := DecodeData(updatedData, target); err != nil {
-		return NewAppError(ErrLocalization, "file_parse_error", map[string]any{
-			"file":  filePath,
-			"error": err,
-		})
+	meta := node.Get("meta")
+	supportedLangs := NewArrayNode(validLangs)
+	if original := meta.Get("supported_languages"); original != nil {
+		supportedLangs.Line, supportedLangs.Column = original.Line, original.Column
 	}
-	return nil
+	meta.Set("supported_languages", supportedLangs)
+	if err := DecodeNode(filePath, node, target); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+func (lm *LocalizationManager) loadData(filePath string, target any) error {
+	node, err := lm.loadDataNode(filePath)
+	if err != nil {
+		return err
+	}
+	return DecodeNode(filePath, node, target)
 }
 
 func (lm *LocalizationManager) updateAvailableLanguages(supportedLangs []string) {
@@ -126,7 +142,7 @@ func (lm *LocalizationManager) updateAvailableLanguages(supportedLangs []string)
 		if _, exists := langMap[code]; !exists {
 			name, ok := lm.langDict[code]
 			if !ok {
-				lm.log("Warning: Language code '%s' not found in dictionary.", code)
+				lm.log("language code not found in dictionary", "code", code)
 				continue
 			}
 			lm.availableLangs = append(lm.availableLangs, Language{Code: code, Name: name})
@@ -228,13 +244,15 @@ func (lm *LocalizationManager) isCoreLocalization(filePath string) bool {
 	return lm.cfg.Paths.IsCorePath(filePath)
 }
 
-func (lm *LocalizationManager) log(format string, v ...any) {
+// log записывает предупреждение: неполадки в данных не мешают игре, но их стоит исправить.
+func (lm *LocalizationManager) log(msg string, args ...any) {
 	if lm.logger == nil {
 		log.Println("Logger is not set.")
 		return
 	}
-	lm.logger.Printf(format, v...)
+	lm.logger.Warn(msg, args...)
 }
+
 func (lm *LocalizationManager) logError(err error) {
 	if lm.logger == nil {
 		log.Println("Logger is not set.")
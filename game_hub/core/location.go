@@ -0,0 +1,81 @@
+package core
+
+import "strings"
+
+// locationSeparator разделяет звенья цепочки, как в заголовке полноэкранного интерфейса
+const locationSeparator = " › "
+
+// Location описывает, где находится игрок: названия состояний стека от главного меню до текущего
+// и краткое положение дел, например оставшиеся попытки.
+type Location struct {
+	// Trail — названия состояний по порядку; состояния без названия пропускаются
+	Trail []string
+	// Game — название запущенной игры; в Trail оно стоит на месте её загрузки
+	Game string
+	// Status — строка состояния текущего состояния или игры
+	Status string
+}
+
+// Breadcrumbs возвращает всю цепочку, например "Главное меню › Выбор игры › Угадай число › Игра".
+func (l Location) Breadcrumbs() string {
+	return strings.Join(l.Trail, locationSeparator)
+}
+
+// Short оставляет от цепочки игру и текущее состояние, чтобы строка ввода не занимала весь экран.
+func (l Location) Short() string {
+	parts := make([]string, 0, 2)
+	if l.Game != "" {
+		parts = append(parts, l.Game)
+	}
+	if current := l.current(); current != "" && current != l.Game {
+		parts = append(parts, current)
+	}
+	text := strings.Join(parts, locationSeparator)
+	if l.Status != "" {
+		text += " | " + l.Status
+	}
+	return text
+}
+
+func (l Location) current() string {
+	if len(l.Trail) == 0 {
+		return ""
+	}
+	return l.Trail[len(l.Trail)-1]
+}
+
+// Location собирает положение игрока по стеку состояний, названию игры и строке состояния.
+func (ui *UiContext) Location(ctx *AppContext) Location {
+	var location Location
+	states := ctx.StateStack.States()
+	for _, state := range states {
+		if title := ui.GetLocalizedStateTitle(ctx, state); title != "" {
+			location.Trail = append(location.Trail, title)
+		}
+	}
+	if ctx.Game != nil {
+		location.Game = ui.GetOptionalLocalizedMsg(ui.AppLocalizer, ctx.Game.GetId(), "name")
+	}
+	if len(states) > 0 {
+		if withStatus, ok := states[len(states)-1].(StateWithStatus); ok {
+			location.Status = withStatus.Status(ctx, ui)
+		}
+	}
+	if withStatus, ok := ctx.Game.(GameWithStatus); ok && location.Status == "" {
+		location.Status = withStatus.Status(ui)
+	}
+	return location
+}
+
+// LocationPrompt возвращает начало строки ввода вида "[Угадай число › Игра | ...] ". В режиме доступности
+// оно не показывается: программа экранного доступа читала бы его перед каждым вводом, а узнать, где вы, можно командой where.
+func (ui *UiContext) LocationPrompt(ctx *AppContext) string {
+	if ui.Accessible() {
+		return ""
+	}
+	short := ui.Location(ctx).Short()
+	if short == "" {
+		return ""
+	}
+	return ui.Theme.Render(Style(StyleHint, "["+short+"]"), ui.colorsEnabled()) + " "
+}
@@ -0,0 +1,93 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const (
+	// logMaxSize — размер файла журнала, после которого он откладывается в архив и начинается новый
+	logMaxSize = 1 << 20
+	// logBackups — сколько прошлых файлов журнала хранится рядом: game_hub.log.1, game_hub.log.2 и так далее
+	logBackups = 3
+)
+
+// RotatingFile — файл журнала, который не растёт бесконечно: при переполнении он переименовывается
+// в <имя>.1, прошлые архивы сдвигаются на номер дальше, а самый старый удаляется.
+type RotatingFile struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+	size int64
+}
+
+// OpenRotatingFile открывает файл журнала для дописывания, создавая недостающие каталоги.
+func OpenRotatingFile(path string) (*RotatingFile, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	r := &RotatingFile{path: path}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.size > 0 && r.size+int64(len(p)) > logMaxSize {
+		// журналу некуда сообщить о своих ошибках: если отложить файл не удалось, запись дописывается в текущий
+		_ = r.rotate()
+	}
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// Flush сбрасывает записанное на диск, чтобы журнал пережил аварийное завершение.
+func (r *RotatingFile) Flush() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.file.Sync()
+}
+
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.file.Close()
+}
+
+func (r *RotatingFile) open() error {
+	file, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		return JoinErrors(err, file.Close())
+	}
+	r.file, r.size = file, info.Size()
+	return nil
+}
+
+// rotate откладывает текущий файл в архив и открывает новый; файл открывается заново даже после ошибки.
+func (r *RotatingFile) rotate() error {
+	if err := r.file.Close(); err != nil {
+		return err
+	}
+	err := r.shiftBackups()
+	return JoinErrors(err, r.open())
+}
+
+func (r *RotatingFile) shiftBackups() error {
+	for i := logBackups - 1; i > 0; i-- {
+		from, to := fmt.Sprintf("%s.%d", r.path, i), fmt.Sprintf("%s.%d", r.path, i+1)
+		if err := os.Rename(from, to); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return os.Rename(r.path, r.path+".1")
+}
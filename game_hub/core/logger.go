@@ -1,36 +1,107 @@
 package core
 
 import (
+	"context"
+	"game_hub/config"
 	"io"
-	"log"
+	"log/slog"
 )
 
+// LevelOff выше любого уровня записей и отключает журнал.
+const LevelOff = slog.Level(100)
+
+// Logger — журнал для разработчиков и отладки; то, с чем игрок может что-то сделать, показывается через UiContext.DisplayError.
 type Logger interface {
-	Printf(format string, v ...any)
+	Debug(msg string, args ...any)
+	Info(msg string, args ...any)
+	Warn(msg string, args ...any)
+	// Error записывает ошибку с её кодом и текстом на языке интерфейса
 	Error(err error)
+	// Flush дописывает буферизованные записи перед завершением программы
+	Flush() error
 }
 
-type StdLogger struct {
-	logger       *log.Logger
+// LevelLogger пишет структурированные записи уровней debug, info, warn и error через log/slog.
+type LevelLogger struct {
+	logger       *slog.Logger
+	output       io.Writer
 	errorHandler ErrorHandler
 }
 
-func NewStdLogger(output io.Writer, errorHandler ErrorHandler) *StdLogger {
-	return &StdLogger{
-		logger:       log.New(output, "", 0),
+// NewFileLogger создаёт журнал для файла: каждая запись — строка key=value со временем и уровнем.
+func NewFileLogger(output io.Writer, level slog.Level, errorHandler ErrorHandler) *LevelLogger {
+	handler := slog.NewTextHandler(output, &slog.HandlerOptions{Level: level})
+	return newLevelLogger(handler, output, errorHandler)
+}
+
+// NewStdLogger создаёт журнал для консоли: время не выводится, остаются уровень, сообщение и поля.
+func NewStdLogger(output io.Writer, level slog.Level, errorHandler ErrorHandler) *LevelLogger {
+	handler := slog.NewTextHandler(output, &slog.HandlerOptions{
+		Level: level,
+		ReplaceAttr: func(groups []string, attr slog.Attr) slog.Attr {
+			if len(groups) == 0 && attr.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return attr
+		},
+	})
+	return newLevelLogger(handler, output, errorHandler)
+}
+
+func newLevelLogger(handler slog.Handler, output io.Writer, errorHandler ErrorHandler) *LevelLogger {
+	return &LevelLogger{
+		logger:       slog.New(handler),
+		output:       output,
 		errorHandler: errorHandler,
 	}
 }
 
-func (l *StdLogger) Printf(format string, v ...interface{}) {
-	l.logger.Printf(format, v...)
+func (l *LevelLogger) Debug(msg string, args ...any) {
+	l.logger.Debug(msg, args...)
+}
+
+func (l *LevelLogger) Info(msg string, args ...any) {
+	l.logger.Info(msg, args...)
+}
+
+func (l *LevelLogger) Warn(msg string, args ...any) {
+	l.logger.Warn(msg, args...)
+}
+
+func (l *LevelLogger) Error(err error) {
+	if err == nil || !l.logger.Enabled(context.Background(), slog.LevelError) {
+		return
+	}
+	text := l.errorHandler.Handle(err)
+	if text == "" {
+		return
+	}
+	code := ErrUnknown
+	if appErr, ok := err.(*AppError); ok {
+		code = appErr.Code
+	}
+	l.logger.Error(text, "code", code)
+}
+
+func (l *LevelLogger) Flush() error {
+	if flusher, ok := l.output.(interface{ Flush() error }); ok {
+		return flusher.Flush()
+	}
+	return nil
 }
 
-func (l *StdLogger) Error(err error) {
-	if err != nil {
-		text := l.errorHandler.Handle(err)
-		if text != "" {
-			l.logger.Println(text)
-		}
+// ParseLogLevel переводит название уровня из флага или настроек в уровень slog.
+func ParseLogLevel(name string) slog.Level {
+	switch name {
+	case config.LogDebug:
+		return slog.LevelDebug
+	case config.LogWarn:
+		return slog.LevelWarn
+	case config.LogError:
+		return slog.LevelError
+	case config.LogOff:
+		return LevelOff
+	default:
+		return slog.LevelInfo
 	}
 }
@@ -1,37 +1,29 @@
 package core
 
-import (
-	"fmt"
-)
-
 type MessageTranslation map[string]string
 
 type MessageTranslations map[string]MessageTranslation
 
-func (m MessageTranslations) isLocalized(langs []string) error {
+func (m MessageTranslations) checkLocalized(diagnostics *DataDiagnostics, path []string, langs []string) {
 	for msgKey, msgTrans := range m {
 		for _, supportedLang := range langs {
-			if _, exists := msgTrans[supportedLang]; exists {
-				continue
+			if _, exists := msgTrans[supportedLang]; !exists {
+				diagnostics.Add(childPath(path, msgKey, supportedLang), missingTranslation(supportedLang))
 			}
-			return NewAppError(Err, "key_not_found", map[string]any{
-				"key": fmt.Sprintf("%s.%s", msgKey, supportedLang),
-			})
 		}
 	}
-	return nil
 }
 
 type OptionalMessageTranslations map[string]MessageTranslations
 
 type MessageLocalizationData struct {
-	Meta         LocalizationMetadata `json:"meta"`
-	Translations MessageTranslations  `json:"translations"`
+	Meta         LocalizationMetadata `json:"meta" validate:"required"`
+	Translations MessageTranslations  `json:"translations" validate:"required"`
 }
 
 type OptionalMessageLocalizationData struct {
-	Meta         LocalizationMetadata        `json:"meta"`
-	Translations OptionalMessageTranslations `json:"translations"`
+	Meta         LocalizationMetadata        `json:"meta" validate:"required"`
+	Translations OptionalMessageTranslations `json:"translations" validate:"required"`
 }
 
 type MessageLocalizer struct {
@@ -50,17 +42,15 @@ func NewMessageLocalizer(lm *LocalizationManager) *MessageLocalizer {
 
 func (l *MessageLocalizer) LoadTranslations(filePath string) error {
 	var rawData MessageLocalizationData
-	if err := l.lm.loadLocalizationData(filePath, &rawData); err != nil {
+	node, err := l.lm.loadLocalizationData(filePath, &rawData)
+	if err != nil {
 		return err
 	}
 	supportedLanguages := rawData.Meta.SupportedLanguages
-	err := rawData.Translations.isLocalized(supportedLanguages)
-	if err != nil {
-		locErr := NewAppError(ErrLocalization, "localization_file_translations_error", map[string]any{
-			"file":  filePath,
-			"path":  "translations",
-			"error": err,
-		})
+	diagnostics := NewDataDiagnostics(filePath, node)
+	rawData.Translations.checkLocalized(diagnostics, []string{"translations"}, supportedLanguages)
+	if err := diagnostics.Err(); err != nil {
+		locErr := missingTranslationsError(err)
 		if !l.lm.isCoreLocalization(filePath) {
 			return locErr
 		}
@@ -75,26 +65,26 @@ func (l *MessageLocalizer) LoadTranslations(filePath string) error {
 
 func (l *MessageLocalizer) LoadOptionalTranslations(filePath string) error {
 	var rawData OptionalMessageLocalizationData
-	if err := l.lm.loadLocalizationData(filePath, &rawData); err != nil {
+	node, err := l.lm.loadLocalizationData(filePath, &rawData)
+	if err != nil {
 		return err
 	}
 	supportedLanguages := rawData.Meta.SupportedLanguages
+	diagnostics := NewDataDiagnostics(filePath, node)
+	for setName, set := range rawData.Translations {
+		set.checkLocalized(diagnostics, []string{"translations", setName}, supportedLanguages)
+	}
+	if err := diagnostics.Err(); err != nil {
+		locErr := missingTranslationsError(err)
+		if !l.lm.isCoreLocalization(filePath) {
+			return locErr
+		}
+		l.lm.logError(locErr)
+	}
 	for setName, set := range rawData.Translations {
 		if _, exists := l.OptionalTranslations[setName]; !exists {
 			l.OptionalTranslations[setName] = make(MessageTranslations)
 		}
-		err := set.isLocalized(supportedLanguages)
-		if err != nil {
-			locErr := NewAppError(ErrLocalization, "localization_file_translations_error", map[string]any{
-				"file":  filePath,
-				"path":  fmt.Sprintf("translations.%s", setName),
-				"error": err,
-			})
-			if !l.lm.isCoreLocalization(filePath) {
-				return locErr
-			}
-			l.lm.logError(locErr)
-		}
 		l.CopyTranslations(l.OptionalTranslations[setName], set)
 	}
 	if l.lm.isCoreLocalization(filePath) {
@@ -0,0 +1,57 @@
+package core
+
+import (
+	"strings"
+)
+
+// CommandCall описывает вызов команды, проходящий через цепочку middleware.
+type CommandCall struct {
+	Command Command
+	Args    []string
+	// State — состояние, в котором была введена команда
+	State State
+	Ctx   *AppContext
+	UI    *UiContext
+}
+
+// CommandHandler выполняет вызов команды и возвращает её результат.
+type CommandHandler func(call *CommandCall) (Transition, error)
+
+// CommandMiddleware оборачивает обработчик: может выполнить код до и после вызова,
+// изменить результат или вовсе не вызывать следующий обработчик.
+type CommandMiddleware func(next CommandHandler) CommandHandler
+
+// CommandHooks превращает пару функций «до» и «после» в middleware.
+// Ошибка из Before отменяет вызов команды; After получает результат вызова.
+func CommandHooks(before func(call *CommandCall) error, after func(call *CommandCall, transition Transition, err error)) CommandMiddleware {
+	return func(next CommandHandler) CommandHandler {
+		return func(call *CommandCall) (Transition, error) {
+			if before != nil {
+				if err := before(call); err != nil {
+					return Stay(), err
+				}
+			}
+			transition, err := next(call)
+			if after != nil {
+				after(call, transition, err)
+			}
+			return transition, err
+		}
+	}
+}
+
+// AuditMiddleware записывает в журнал каждую выполненную команду и её результат.
+func AuditMiddleware(logger Logger) CommandMiddleware {
+	return CommandHooks(nil, func(call *CommandCall, transition Transition, err error) {
+		stateId := ""
+		if call.State != nil {
+			stateId = call.State.Id()
+		}
+		logger.Debug("command", "id", call.Command.Id(), "args", strings.Join(call.Args[1:], " "), "state", stateId, "transition", transition.Kind, "error", err)
+	})
+}
+
+// executeCommand — последнее звено цепочки: сам вызов команды.
+func executeCommand(call *CommandCall) (Transition, error) {
+	return call.Command.Execute(call.Ctx, call.UI, call.Args)
+}
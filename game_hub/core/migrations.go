@@ -0,0 +1,107 @@
+package core
+
+import (
+	"game_hub/config"
+	"strconv"
+)
+
+// Migration переводит дерево файла данных с одной версии схемы на следующую.
+type Migration func(root *DataNode) error
+
+// migrations хранит цепочки миграций для каждого вида файлов: элемент с индексом i переводит версию i в i+1.
+var migrations = map[config.DataFileKind][]Migration{
+	config.MessagesFile:    {addSchemaVersion},
+	config.MessageSetsFile: {addSchemaVersion},
+	config.StatesFile:      {addSchemaVersion},
+	config.CommandsFile:    {addSchemaVersion},
+	config.LanguagesFile:   {addSchemaVersion},
+	config.SettingsFile:    {addSchemaVersion},
+	config.GameSaveFile:    {addSchemaVersion},
+	config.ThemeFile:       {addSchemaVersion},
+}
+
+// RegisterMigration добавляет в цепочку следующую миграцию и тем самым повышает текущую версию схемы.
+func RegisterMigration(kind config.DataFileKind, migration Migration) {
+	migrations[kind] = append(migrations[kind], migration)
+}
+
+func SchemaVersion(kind config.DataFileKind) int {
+	return len(migrations[kind])
+}
+
+// addSchemaVersion — первая миграция: до неё файлы не содержали версии, а сама структура не менялась.
+func addSchemaVersion(root *DataNode) error {
+	return nil
+}
+
+// MigrateNode обновляет дерево до текущей версии схемы и возвращает версию, с которой оно было обновлено.
+func MigrateNode(filePath string, kind config.DataFileKind, root *DataNode) (int, error) {
+	version, err := fileSchemaVersion(filePath, root)
+	if err != nil {
+		return 0, err
+	}
+	current := SchemaVersion(kind)
+	if version > current {
+		return version, NewAppError(Err, "schema_version_unsupported", map[string]any{
+			"file":      filePath,
+			"version":   version,
+			"supported": current,
+		})
+	}
+	for v := version; v < current; v++ {
+		if err := migrations[kind][v](root); err != nil {
+			return version, NewAppError(Err, "migration_error", map[string]any{
+				"file":    filePath,
+				"version": v + 1,
+				"error":   err,
+			})
+		}
+	}
+	if version < current {
+		setSchemaVersion(root, current)
+	}
+	return version, nil
+}
+
+func fileSchemaVersion(filePath string, root *DataNode) (int, error) {
+	node := root.Find([]string{"meta", "schema_version"})
+	if node == nil || node.Kind == NodeNull {
+		return 0, nil
+	}
+	version, err := strconv.Atoi(node.Value)
+	if node.Kind != NodeNumber || err != nil || version < 0 {
+		diagnostics := NewDataDiagnostics(filePath, root)
+		diagnostics.Add([]string{"meta", "schema_version"}, NewAppError(Err, "data_type_mismatch", map[string]any{
+			"expected": NewAppError(Err, "data_kind_integer", nil),
+			"found":    node.Value,
+		}))
+		return 0, diagnostics.Err()
+	}
+	return version, nil
+}
+
+// setSchemaVersion ставит meta и schema_version в начало, чтобы версия была видна сразу при открытии файла.
+func setSchemaVersion(root *DataNode, version int) {
+	meta := root.Get("meta")
+	if meta != nil && meta.Kind != NodeObject {
+		return
+	}
+	if meta == nil {
+		meta = NewObjectNode()
+		root.Set("meta", meta)
+		moveKeyFirst(root, "meta")
+	}
+	meta.Set("schema_version", &DataNode{Kind: NodeNumber, Value: strconv.Itoa(version)})
+	moveKeyFirst(meta, "schema_version")
+}
+
+func moveKeyFirst(node *DataNode, key string) {
+	keys := make([]string, 0, len(node.Keys))
+	keys = append(keys, key)
+	for _, k := range node.Keys {
+		if k != key {
+			keys = append(keys, k)
+		}
+	}
+	node.Keys = keys
+}
@@ -0,0 +1,143 @@
+package core
+
+import (
+	"game_hub/utils"
+	"strings"
+)
+
+// DisplayPaged выводит длинный текст, например справку или статистику, постранично, как more:
+// если текст не помещается на экран, возвращается переход к PagerState, иначе текст выводится сразу и возвращается Stay.
+// Делить на страницы можно, только когда и ввод, и вывод подключены к терминалу; иначе текст выводится целиком.
+func (ui *UiContext) DisplayPaged(txt string) Transition {
+	text := ui.policy().Format(ui, txt)
+	height := ui.pageHeight()
+	lines := strings.SplitAfter(text, "\n")
+	if height == 0 || ui.rows(lines) < height {
+		ui.write(text)
+		return Stay()
+	}
+	pager := &PagerState{}
+	// одна строка экрана остаётся под подсказку
+	pageRows := max(height-1, 1)
+	for start := 0; start < len(lines); {
+		end, used := start, 0
+		for end < len(lines) && (used == 0 || used+ui.rows(lines[end:end+1]) <= pageRows) {
+			used += ui.rows(lines[end : end+1])
+			end++
+		}
+		pager.pages = append(pager.pages, strings.Join(lines[start:end], ""))
+		pager.percents = append(pager.percents, end*100/len(lines))
+		start = end
+	}
+	return Push(pager)
+}
+
+// PagerState показывает страницы текста по одной. Ответ на подсказку читает движок, как и любой другой ввод,
+// поэтому во время просмотра работают Ctrl+C, завершение работы и таймеры.
+// После последней страницы или по q и Escape просмотр закрывается, и предыдущее состояние показывается заново.
+type PagerState struct {
+	BaseState
+	pages []string
+	// percents — доля текста, показанная вместе с соответствующей страницей
+	percents []int
+	page     int
+}
+
+func (p *PagerState) Id() string {
+	return "pager"
+}
+
+// Display выводит текущую страницу; без посимвольного ввода подсказка выводится отдельной строкой.
+func (p *PagerState) Display(_ *AppContext, ui *UiContext) {
+	ui.write(p.pages[p.page])
+	if _, ok := ui.KeyReader(); !ok {
+		ui.DisplayText(utils.SubstituteParams(ui.GetLocalizedMsg(ui.AppLocalizer, "pager_prompt"), p.params()) + "\r\n")
+	}
+}
+
+// Handle листает страницы в режиме доступности, где ответ вводится строкой.
+func (p *PagerState) Handle(_ *AppContext, ui *UiContext, input string) (Transition, error) {
+	if isPagerQuit(input, ui.GetLocalizedMsg(ui.AppLocalizer, "pager_quit")) {
+		return Pop(), nil
+	}
+	return p.next(ui), nil
+}
+
+func (p *PagerState) GetCommands() []Command {
+	return []Command{
+		&StopReadingCommand{},
+	}
+}
+
+func (p *PagerState) KeyPrompt(_ *AppContext, ui *UiContext) string {
+	prompt := utils.SubstituteParams(ui.GetLocalizedMsg(ui.AppLocalizer, "pager_prompt_keys"), p.params())
+	return ui.Theme.Render(Style(StyleHint, prompt), ui.colorsEnabled())
+}
+
+// HandleKey открывает следующую страницу по любой клавише, кроме q и Escape: строка команды из просмотра не начинается.
+func (p *PagerState) HandleKey(_ *AppContext, ui *UiContext, key Key) (Transition, KeyResult, error) {
+	quit := key.Code == KeyEscape ||
+		key.Code == KeyRune && isPagerQuit(string(key.Rune), ui.GetLocalizedMsg(ui.AppLocalizer, "pager_quit"))
+	if quit {
+		return Pop(), KeyHandled, nil
+	}
+	return p.next(ui), KeyHandled, nil
+}
+
+// next переходит к следующей странице; последнюю ответа не ждёт: она выводится сразу, и просмотр закрывается.
+func (p *PagerState) next(ui *UiContext) Transition {
+	p.page++
+	if p.page < len(p.pages)-1 {
+		return Stay()
+	}
+	ui.write(p.pages[len(p.pages)-1])
+	return Pop()
+}
+
+func (p *PagerState) params() map[string]any {
+	return map[string]any{"percent": p.percents[p.page]}
+}
+
+func (ui *UiContext) write(text string) {
+	if err := ui.Console.Write(text); err != nil {
+		ui.logError(err)
+	}
+}
+
+// pageHeight возвращает высоту страницы или 0, если вывод не делится на страницы.
+func (ui *UiContext) pageHeight() int {
+	keys, ok := ui.Console.(KeyReader)
+	if !ok || !keys.SupportsKeys() {
+		// без терминала на вводе ответ на подсказку съел бы строки, предназначенные игре
+		return 0
+	}
+	if sized, ok := ui.Console.(TextHeightProvider); ok {
+		return sized.TextHeight()
+	}
+	return 0
+}
+
+// rows считает, сколько строк экрана займут строки текста; в режиме доступности длинные строки не переносятся
+// заранее, но терминал всё равно разбивает их по своей ширине.
+func (ui *UiContext) rows(lines []string) int {
+	width := 0
+	if sized, ok := ui.Console.(TextWidthProvider); ok {
+		width = sized.TextWidth()
+	}
+	total := 0
+	for _, line := range lines {
+		line = strings.TrimRight(line, "\r\n")
+		if width <= 0 {
+			total++
+			continue
+		}
+		total += max((utils.DisplayWidth(line)+width-1)/width, 1)
+	}
+	return total
+}
+
+// isPagerQuit узнаёт ответ «закончить»: латинскую q или её перевод, например русскую й на той же клавише.
+func isPagerQuit(answer, quit string) bool {
+	answer = strings.TrimSpace(answer)
+	return strings.EqualFold(answer, "q") || (quit != "" && strings.EqualFold(answer, quit))
+}
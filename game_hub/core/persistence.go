@@ -0,0 +1,145 @@
+package core
+
+import (
+	"game_hub/config"
+	"os"
+)
+
+// Settings хранит пользовательские настройки, которые переживают перезапуск программы.
+type Settings struct {
+	Meta       SchemaMetadata `json:"meta"`
+	Language   string         `json:"language"`
+	Accessible bool           `json:"accessible,omitempty"`
+	LogLevel   string         `json:"log_level,omitempty" validate:"omitempty,oneof=debug info warn error off"`
+	// HistorySize — сколько строк хранится в каждой истории ввода; 0 отключает историю
+	HistorySize *int `json:"history_size,omitempty" validate:"omitempty,min=0,max=10000"`
+}
+
+// SaveableGame реализуют игры, которые сохраняют свои данные между запусками.
+// SaveData возвращает указатель на структуру: из неё данные записываются, и в неё же они восстанавливаются.
+type SaveableGame interface {
+	GameInterface
+	SaveData() any
+}
+
+// ResumableGame реализуют игры, которые сохраняют и незаконченную партию, чтобы продолжить её после перезапуска.
+// Сохранение читается не в SaveData, а в RestoreData: иначе партия, записанная командой save посреди игры,
+// вернулась бы при следующем переходе к началу игры.
+type ResumableGame interface {
+	SaveableGame
+	// InProgress сообщает, идёт ли сейчас партия, которая попадёт в сохранение.
+	InProgress() bool
+	// RestoreData возвращает указатель на структуру, в которую читается сохранение при загрузке игры.
+	RestoreData() any
+}
+
+type gameSave struct {
+	Meta SchemaMetadata `json:"meta"`
+	Game any            `json:"game"`
+}
+
+// LoadSettings читает настройки пользователя; если их ещё нет, возвращает nil без ошибки.
+func LoadSettings(cfg *config.Config) (*Settings, error) {
+	filePath := cfg.Paths.SettingsPath()
+	root, err := loadUserData(cfg, filePath)
+	if root == nil || err != nil {
+		return nil, err
+	}
+	var settings Settings
+	if err := DecodeNode(filePath, root, &settings); err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+// SaveSettings записывает текущие настройки; поля, которые меняются только в самом файле, например log_level и history_size, сохраняются как были.
+func SaveSettings(ctx *AppContext, ui *UiContext) error {
+	settings, err := LoadSettings(ctx.Config)
+	if err != nil || settings == nil {
+		settings = &Settings{}
+	}
+	settings.Meta = SchemaMetadata{SchemaVersion: SchemaVersion(config.SettingsFile)}
+	settings.Language = ui.LocalizationManager.CurrentLang()
+	settings.Accessible = ui.Accessible()
+	return saveUserData(ctx.Config.Paths.SettingsPath(), settings)
+}
+
+// SaveGame сохраняет данные текущей игры, если она это поддерживает.
+func SaveGame(ctx *AppContext) error {
+	game, ok := ctx.Game.(SaveableGame)
+	if !ok {
+		return nil
+	}
+	return saveUserData(ctx.Config.Paths.GameSavePath(game.GetId()), &gameSave{
+		Meta: SchemaMetadata{SchemaVersion: SchemaVersion(config.GameSaveFile)},
+		Game: game.SaveData(),
+	})
+}
+
+// RestoreGame загружает в игру сохранённые ранее данные, если они есть.
+func RestoreGame(ctx *AppContext, game GameInterface) error {
+	saveable, ok := game.(SaveableGame)
+	if !ok {
+		return nil
+	}
+	filePath := ctx.Config.Paths.GameSavePath(game.GetId())
+	root, err := loadUserData(ctx.Config, filePath)
+	if root == nil || err != nil {
+		return err
+	}
+	node := root.Get("game")
+	if node == nil {
+		return nil
+	}
+	target := saveable.SaveData()
+	if resumable, ok := game.(ResumableGame); ok {
+		target = resumable.RestoreData()
+	}
+	return DecodeNode(filePath, node, target)
+}
+
+// Autosave сохраняет настройки и текущую игру; ошибки собираются, чтобы одна не мешала другой.
+func Autosave(ctx *AppContext, ui *UiContext) error {
+	err := JoinErrors(SaveSettings(ctx, ui), SaveGame(ctx))
+	if err == nil {
+		return nil
+	}
+	return NewAppError(Err, "autosave_error", map[string]any{
+		"error": err,
+	})
+}
+
+// loadUserData читает файл пользователя и обновляет его схему; отсутствующий файл не считается ошибкой.
+func loadUserData(cfg *config.Config, filePath string) (*DataNode, error) {
+	if _, err := os.Stat(filePath); os.IsNotExist(err) {
+		return nil, nil
+	}
+	data, err := ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	root, err := ParseDataFile(filePath, data)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := MigrateNode(filePath, cfg.Paths.DataFileKind(filePath), root); err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+func saveUserData(filePath string, data any) error {
+	encoded, err := EncodeData(data)
+	if err != nil {
+		return err
+	}
+	root, err := ParseDataFile(filePath, encoded)
+	if err != nil {
+		return err
+	}
+	formatted, err := EncodeDataFile(filePath, root)
+	if err != nil {
+		return err
+	}
+	return WriteFile(filePath, formatted)
+}
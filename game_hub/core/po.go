@@ -0,0 +1,204 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PoEntry описывает одну запись файла переводов gettext.
+type PoEntry struct {
+	Comments  []string
+	Reference string
+	Flags     []string
+	Context   string
+	Id        string
+	Str       string
+}
+
+type PoFile struct {
+	Header  map[string]string
+	Entries []PoEntry
+}
+
+func NewPoFile() *PoFile {
+	return &PoFile{
+		Header:  make(map[string]string),
+		Entries: make([]PoEntry, 0),
+	}
+}
+
+var poHeaderOrder = []string{
+	"Project-Id-Version",
+	"Language",
+	"MIME-Version",
+	"Content-Type",
+	"Content-Transfer-Encoding",
+	"X-Source-Language",
+	"X-Source-File",
+}
+
+func (f *PoFile) Encode() []byte {
+	var buf bytes.Buffer
+	buf.WriteString("msgid \"\"\nmsgstr \"\"\n")
+	for _, key := range poHeaderOrder {
+		if value, exists := f.Header[key]; exists {
+			buf.WriteString(quotePoString(fmt.Sprintf("%s: %s\n", key, value)) + "\n")
+		}
+	}
+	for _, entry := range f.Entries {
+		buf.WriteString("\n")
+		for _, comment := range entry.Comments {
+			buf.WriteString("#. " + comment + "\n")
+		}
+		if entry.Reference != "" {
+			buf.WriteString("#: " + entry.Reference + "\n")
+		}
+		if len(entry.Flags) > 0 {
+			buf.WriteString("#, " + strings.Join(entry.Flags, ", ") + "\n")
+		}
+		buf.WriteString("msgctxt " + quotePoString(entry.Context) + "\n")
+		buf.WriteString("msgid " + encodePoString(entry.Id) + "\n")
+		buf.WriteString("msgstr " + encodePoString(entry.Str) + "\n")
+	}
+	return buf.Bytes()
+}
+
+// encodePoString разбивает многострочный текст на несколько строк, как это делают редакторы переводов.
+func encodePoString(s string) string {
+	if !strings.Contains(strings.TrimSuffix(s, "\n"), "\n") {
+		return quotePoString(s)
+	}
+	lines := strings.SplitAfter(s, "\n")
+	parts := make([]string, 0, len(lines)+1)
+	parts = append(parts, `""`)
+	for _, line := range lines {
+		if line != "" {
+			parts = append(parts, quotePoString(line))
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+func quotePoString(s string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+	return `"` + replacer.Replace(s) + `"`
+}
+
+func ParsePo(data []byte) (*PoFile, error) {
+	file := NewPoFile()
+	var entry PoEntry
+	var target *string
+	hasEntry := false
+	flush := func() {
+		if hasEntry {
+			if entry.Context == "" && entry.Id == "" {
+				file.parseHeader(entry.Str)
+			} else {
+				file.Entries = append(file.Entries, entry)
+			}
+		}
+		entry = PoEntry{}
+		target = nil
+		hasEntry = false
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "":
+			flush()
+		case strings.HasPrefix(line, "#~"):
+			continue
+		case strings.HasPrefix(line, "#"):
+			if hasEntry && target != nil {
+				flush()
+			}
+			switch {
+			case strings.HasPrefix(line, "#."):
+				entry.Comments = append(entry.Comments, strings.TrimSpace(line[2:]))
+			case strings.HasPrefix(line, "#:"):
+				entry.Reference = strings.TrimSpace(line[2:])
+			case strings.HasPrefix(line, "#,"):
+				for _, flag := range strings.Split(line[2:], ",") {
+					entry.Flags = append(entry.Flags, strings.TrimSpace(flag))
+				}
+			}
+		case strings.HasPrefix(line, `"`):
+			if target == nil {
+				return nil, poSyntaxError(lineNum, line)
+			}
+			value, err := strconv.Unquote(line)
+			if err != nil {
+				return nil, poSyntaxError(lineNum, line)
+			}
+			*target += value
+		default:
+			keyword, rest, found := strings.Cut(line, " ")
+			if !found {
+				return nil, poSyntaxError(lineNum, line)
+			}
+			value, err := strconv.Unquote(strings.TrimSpace(rest))
+			if err != nil {
+				return nil, poSyntaxError(lineNum, line)
+			}
+			switch keyword {
+			case "msgctxt":
+				if hasEntry && target != nil {
+					flush()
+				}
+				entry.Context = value
+				target = &entry.Context
+			case "msgid":
+				if target == &entry.Str {
+					flush()
+				}
+				entry.Id = value
+				target = &entry.Id
+			case "msgstr":
+				entry.Str = value
+				target = &entry.Str
+			default:
+				target = nil
+			}
+			hasEntry = true
+		}
+	}
+	flush()
+	if err := scanner.Err(); err != nil {
+		return nil, NewAppError(Err, "decode_error", map[string]any{
+			"error": err,
+		})
+	}
+	return file, nil
+}
+
+func (f *PoFile) parseHeader(header string) {
+	for _, line := range strings.Split(header, "\n") {
+		key, value, found := strings.Cut(line, ":")
+		if found {
+			f.Header[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+}
+
+func (e PoEntry) isFuzzy() bool {
+	for _, flag := range e.Flags {
+		if flag == "fuzzy" {
+			return true
+		}
+	}
+	return false
+}
+
+func poSyntaxError(line int, text string) error {
+	return NewAppError(Err, "po_syntax_error", map[string]any{
+		"line": line,
+		"text": text,
+	})
+}
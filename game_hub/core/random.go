@@ -3,9 +3,21 @@ package core
 import (
 	"math"
 	"math/rand"
+	"sync"
 	"time"
 )
 
+// sessionSeed определяет все случайные числа сеанса, поэтому по нему из отчёта о сбое можно воспроизвести игру.
+var (
+	sessionSeed  = time.Now().UnixNano()
+	seedSource   = rand.New(rand.NewSource(sessionSeed))
+	seedSourceMu sync.Mutex
+)
+
+func SessionSeed() int64 {
+	return sessionSeed
+}
+
 type RandomGenerator struct {
 	rand           *rand.Rand
 	minRangeNumber int
@@ -14,7 +26,7 @@ type RandomGenerator struct {
 
 func NewRandomGenerator() *RandomGenerator {
 	return &RandomGenerator{
-		rand:           rand.New(rand.NewSource(time.Now().UnixNano())),
+		rand:           rand.New(rand.NewSource(nextSeed())),
 		minRangeNumber: 0,
 		maxRangeNumber: math.MaxInt32,
 	}
@@ -29,3 +41,10 @@ func (r *RandomGenerator) Generate(from, to int) (int, error) {
 	}
 	return r.rand.Intn(to-from+1) + from, nil
 }
+
+// nextSeed выдаёт каждому генератору своё зерно, производное от зерна сеанса.
+func nextSeed() int64 {
+	seedSourceMu.Lock()
+	defer seedSourceMu.Unlock()
+	return seedSource.Int63()
+}
@@ -0,0 +1,84 @@
+package core
+
+import (
+	"game_hub/utils"
+	"regexp"
+	"strings"
+)
+
+// RenderPolicy решает, в каком виде текст состояний попадает в консоль.
+type RenderPolicy interface {
+	// Format превращает размеченный текст в строку для консоли
+	Format(ui *UiContext, text string) string
+	// Accessible сообщает, что вывод рассчитан на программы экранного доступа: состояния берут
+	// доступные варианты сообщений, меню называют число пунктов, а ввод идёт строками
+	Accessible() bool
+}
+
+// VisualPolicy — обычный вывод: стили темы и перенос по ширине консоли.
+type VisualPolicy struct{}
+
+func (VisualPolicy) Format(ui *UiContext, text string) string {
+	text = ui.Theme.Render(text, ui.colorsEnabled())
+	return utils.WrapText(text, ui.textWidth())
+}
+
+func (VisualPolicy) Accessible() bool {
+	return false
+}
+
+// AccessiblePolicy — вывод для программ экранного доступа: текст не переносится и не оформляется,
+// а псевдографика и декоративные разделители пропускаются.
+type AccessiblePolicy struct{}
+
+func (AccessiblePolicy) Format(ui *UiContext, text string) string {
+	return ui.Theme.Render(omitDecoration(text), false)
+}
+
+func (AccessiblePolicy) Accessible() bool {
+	return true
+}
+
+var (
+	// separatorLine — строка из одних повторяющихся знаков вроде "-----" или "=====", которые читаются вслух как шум
+	separatorLine  = regexp.MustCompile(`^[ \t]*([-=*~_#+.•─━═]{3,}[ \t]*)+$`)
+	separatorChars = regexp.MustCompile(`[-=*~_#+.•─━═]{3}`)
+)
+
+// omitDecoration убирает фрагменты {decor}...{/decor} и строки-разделители; строка, от которой после этого
+// ничего не осталось, пропускается целиком, чтобы не читать вслух пустые строки.
+func omitDecoration(text string) string {
+	open, closing := "{"+StyleDecor+"}", "{/"+StyleDecor+"}"
+	if !strings.Contains(text, open) && !separatorChars.MatchString(text) {
+		return text
+	}
+	// вырезанный фрагмент отмечается нулевым символом, чтобы потом узнать опустевшие строки
+	var b strings.Builder
+	for {
+		start := strings.Index(text, open)
+		if start < 0 {
+			break
+		}
+		b.WriteString(text[:start] + "\x00")
+		end := strings.Index(text[start:], closing)
+		if end < 0 {
+			text = ""
+			break
+		}
+		// переводы строк внутри фрагмента сохраняются, чтобы каждая строка псевдографики опустела отдельно
+		b.WriteString(strings.Repeat("\n\x00", strings.Count(text[start:start+end], "\n")))
+		text = text[start+end+len(closing):]
+	}
+	b.WriteString(text)
+	var result strings.Builder
+	for _, line := range strings.SplitAfter(b.String(), "\n") {
+		body := strings.TrimRight(line, "\r\n")
+		cut := strings.Contains(body, "\x00")
+		body = strings.ReplaceAll(body, "\x00", "")
+		if separatorLine.MatchString(body) || (cut && strings.TrimSpace(body) == "") {
+			continue
+		}
+		result.WriteString(body + line[len(strings.TrimRight(line, "\r\n")):])
+	}
+	return result.String()
+}
@@ -0,0 +1,109 @@
+package core
+
+import (
+	"sync"
+	"time"
+)
+
+// TimerFired приходит состоянию, когда истекает запланированный им таймер.
+type TimerFired struct {
+	Id  string
+	seq int
+}
+
+func (e TimerFired) EventName() string {
+	return "timer_fired"
+}
+
+// EventHandler реализуют состояния, которые реагируют на таймеры и асинхронные события, не дожидаясь ввода.
+type EventHandler interface {
+	HandleEvent(ctx *AppContext, ui *UiContext, event Event) (Transition, error)
+}
+
+type scheduledTimer struct {
+	timer *time.Timer
+	seq   int
+}
+
+// Scheduler передаёт таймеры и события из других горутин в цикл движка.
+type Scheduler struct {
+	mu     sync.Mutex
+	events chan Event
+	timers map[string]scheduledTimer
+	seq    int
+	// done закрывается, когда цикл движка перестаёт читать события, чтобы отправители не ждали вечно
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+func NewScheduler() *Scheduler {
+	return &Scheduler{
+		events: make(chan Event, 16),
+		timers: make(map[string]scheduledTimer),
+		done:   make(chan struct{}),
+	}
+}
+
+// After планирует событие TimerFired с заданным id; повторный вызов с тем же id перезапускает таймер.
+func (s *Scheduler) After(id string, delay time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if scheduled, exists := s.timers[id]; exists {
+		scheduled.timer.Stop()
+	}
+	s.seq++
+	event := TimerFired{Id: id, seq: s.seq}
+	s.timers[id] = scheduledTimer{
+		timer: time.AfterFunc(delay, func() { s.Post(event) }),
+		seq:   event.seq,
+	}
+}
+
+func (s *Scheduler) Cancel(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if scheduled, exists := s.timers[id]; exists {
+		scheduled.timer.Stop()
+		delete(s.timers, id)
+	}
+}
+
+func (s *Scheduler) CancelAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, scheduled := range s.timers {
+		scheduled.timer.Stop()
+		delete(s.timers, id)
+	}
+}
+
+// Post передаёт событие в цикл движка; безопасно вызывать из любой горутины.
+// Пока очередь полна, Post ждёт, а после Stop событие отбрасывается: читать его уже некому.
+func (s *Scheduler) Post(event Event) {
+	select {
+	case s.events <- event:
+	case <-s.done:
+	}
+}
+
+// Stop отменяет все таймеры и отпускает горутины, ждущие в Post; повторный вызов ничего не делает.
+func (s *Scheduler) Stop() {
+	s.CancelAll()
+	s.stopOnce.Do(func() { close(s.done) })
+}
+
+func (s *Scheduler) Events() <-chan Event {
+	return s.events
+}
+
+// claim проверяет, что сработавший таймер не был отменён или перезапущен, пока событие шло в цикл.
+func (s *Scheduler) claim(event TimerFired) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	scheduled, exists := s.timers[event.Id]
+	if !exists || scheduled.seq != event.seq {
+		return false
+	}
+	delete(s.timers, event.Id)
+	return true
+}
@@ -1,8 +1,10 @@
 package core
 
+import "time"
+
 type State interface {
 	Init(ctx *AppContext, ui *UiContext) (State, error)
-	Handle(ctx *AppContext, ui *UiContext, input string) (State, error)
+	Handle(ctx *AppContext, ui *UiContext, input string) (Transition, error)
 	Display(ctx *AppContext, ui *UiContext)
 	GetCommands() []Command
 	RequiresInput() bool
@@ -10,6 +12,40 @@ type State interface {
 	Scope() Scope
 }
 
+// Необязательные интерфейсы жизненного цикла: движок вызывает их методы, только если состояние их реализует.
+
+// EnterHook вызывается после Init, когда состояние открыто переходом Push или Replace.
+type EnterHook interface {
+	OnEnter(ctx *AppContext, ui *UiContext) error
+}
+
+// ExitHook вызывается, когда состояние убирается из стека.
+type ExitHook interface {
+	OnExit(ctx *AppContext, ui *UiContext) error
+}
+
+// PauseHook вызывается, когда поверх состояния открывается другое, например диалог подтверждения.
+type PauseHook interface {
+	OnPause(ctx *AppContext, ui *UiContext) error
+}
+
+// ResumeHook вызывается, когда состояние снова становится текущим после возврата назад.
+type ResumeHook interface {
+	OnResume(ctx *AppContext, ui *UiContext) error
+}
+
+// TitledState реализуют состояния, название которых зависит от данных, а не только от "title" в states.json.
+type TitledState interface {
+	Title(ctx *AppContext, ui *UiContext) string
+}
+
+// StateWithStatus реализуют состояния, которые могут кратко описать своё положение, например шаг настройки;
+// в строке ввода оно показывается вместо строки состояния игры.
+type StateWithStatus interface {
+	// Status возвращает локализованную строку состояния; пустая строка — показывать нечего
+	Status(ctx *AppContext, ui *UiContext) string
+}
+
 type BaseState struct{}
 
 func (b *BaseState) Id() string {
@@ -34,6 +70,35 @@ func (b *BaseState) Init(ctx *AppContext, ui *UiContext) (State, error) {
 
 func (b *BaseState) Display(ctx *AppContext, ui *UiContext) {}
 
-func (b *BaseState) Handle(ctx *AppContext, ui *UiContext, input string) (State, error) {
-	return b, nil
+func (b *BaseState) Handle(ctx *AppContext, ui *UiContext, input string) (Transition, error) {
+	return Stay(), nil
+}
+
+// KeyResult сообщает движку, как состояние распорядилось нажатием.
+type KeyResult int
+
+const (
+	// KeyIgnored — клавиша не нужна состоянию и начинает обычный ввод строки, например команды
+	KeyIgnored KeyResult = iota
+	// KeyHandled — клавиша сработала как введённая строка: при Stay состояние показывается заново
+	KeyHandled
+	// KeyConsumed — изменилась только строка ввода, например подсветка в меню, экран перерисовывать не нужно
+	KeyConsumed
+)
+
+// KeyHandler реализуют состояния, которыми в терминале можно управлять отдельными клавишами, без Enter.
+// Если ввод идёт не из терминала, движок читает строки и передаёт их в Handle, как обычно.
+type KeyHandler interface {
+	// KeyPrompt возвращает строку ввода, которую видно, пока состояние ждёт клавишу
+	KeyPrompt(ctx *AppContext, ui *UiContext) string
+	HandleKey(ctx *AppContext, ui *UiContext, key Key) (Transition, KeyResult, error)
+}
+
+// InputDeadline реализуют состояния, которые ждут ввода ограниченное время, например игра на скорость.
+// Отсчёт начинается каждый раз, когда состояние показано, и продолжается, пока игрок набирает команды.
+type InputDeadline interface {
+	// InputTimeout возвращает время на ввод; ноль означает ожидание без ограничения
+	InputTimeout(ctx *AppContext, ui *UiContext) time.Duration
+	// HandleTimeout вызывается вместо Handle, если за отведённое время ничего не введено
+	HandleTimeout(ctx *AppContext, ui *UiContext) (Transition, error)
 }
@@ -1,44 +1,52 @@
 package core
 
-import (
-	"fmt"
-)
-
 type StateTranslation struct {
+	// Title — необязательное короткое название состояния для строки ввода и команды where
+	Title       map[string]string            `json:"title"`
 	Description map[string]string            `json:"description"`
 	Messages    map[string]map[string]string `json:"messages"`
+	// Accessible — необязательные варианты сообщений для режима доступности с теми же параметрами, что и в Messages
+	Accessible map[string]map[string]string `json:"accessible"`
 }
 
-func (s StateTranslation) isLocalized(langs []string) error {
+func (s StateTranslation) checkLocalized(diagnostics *DataDiagnostics, path []string, langs []string) {
 	for _, supportedLang := range langs {
 		if len(s.Description) == 0 {
 			break
 		}
-		if _, exists := s.Description[supportedLang]; exists {
-			continue
+		if _, exists := s.Description[supportedLang]; !exists {
+			diagnostics.Add(childPath(path, "description", supportedLang), missingTranslation(supportedLang))
+		}
+	}
+	for _, supportedLang := range langs {
+		if len(s.Title) == 0 {
+			break
+		}
+		if _, exists := s.Title[supportedLang]; !exists {
+			diagnostics.Add(childPath(path, "title", supportedLang), missingTranslation(supportedLang))
 		}
-		return NewAppError(Err, "key_not_found", map[string]any{
-			"key": fmt.Sprintf("description.%s", supportedLang),
-		})
 	}
 	for msgKey, msgTrans := range s.Messages {
 		for _, supportedLang := range langs {
-			if _, exists := msgTrans[supportedLang]; exists {
-				continue
+			if _, exists := msgTrans[supportedLang]; !exists {
+				diagnostics.Add(childPath(path, "messages", msgKey, supportedLang), missingTranslation(supportedLang))
+			}
+		}
+	}
+	for msgKey, msgTrans := range s.Accessible {
+		for _, supportedLang := range langs {
+			if _, exists := msgTrans[supportedLang]; !exists {
+				diagnostics.Add(childPath(path, "accessible", msgKey, supportedLang), missingTranslation(supportedLang))
 			}
-			return NewAppError(Err, "key_not_found", map[string]any{
-				"key": fmt.Sprintf("Messages.%s.%s", msgKey, supportedLang),
-			})
 		}
 	}
-	return nil
 }
 
 type StateTranslations map[Scope]map[string]StateTranslation
 
 type StateLocalizationData struct {
-	Meta         LocalizationMetadata `json:"meta"`
-	Translations StateTranslations    `json:"translations"`
+	Meta         LocalizationMetadata `json:"meta" validate:"required"`
+	Translations StateTranslations    `json:"translations" validate:"required"`
 }
 
 type StateLocalizer struct {
@@ -55,18 +63,37 @@ func NewStateLocalizer(lm *LocalizationManager) *StateLocalizer {
 
 func (l *StateLocalizer) LoadTranslations(filePath string) error {
 	var rawData StateLocalizationData
-	if err := l.lm.loadLocalizationData(filePath, &rawData); err != nil {
+	node, err := l.lm.loadLocalizationData(filePath, &rawData)
+	if err != nil {
 		return err
 	}
 
 	supportedLanguages := rawData.Meta.SupportedLanguages
+	invalid := NewDataDiagnostics(filePath, node)
+	diagnostics := NewDataDiagnostics(filePath, node)
 	for scope, states := range rawData.Translations {
 		if !scope.IsValid() {
-			return NewAppError(ErrLocalization, "invalid_state_localizations_scope", map[string]any{
-				"file":  filePath,
-				"scope": scope,
-			})
+			invalid.Add([]string{"translations", string(scope)}, invalidScope(scope))
+			continue
 		}
+		for stateId, trans := range states {
+			trans.checkLocalized(diagnostics, []string{"translations", string(scope), stateId}, supportedLanguages)
+		}
+	}
+	if err := invalid.Err(); err != nil {
+		return NewAppError(ErrLocalization, "localization_file_error", map[string]any{
+			"file":  filePath,
+			"error": err,
+		})
+	}
+	if err := diagnostics.Err(); err != nil {
+		locErr := missingTranslationsError(err)
+		if l.lm.isCoreLocalization(filePath) {
+			return locErr
+		}
+		l.lm.logError(locErr)
+	}
+	for scope, states := range rawData.Translations {
 		if _, exists := l.Translations[scope]; !exists {
 			l.Translations[scope] = make(map[string]StateTranslation)
 		}
@@ -77,18 +104,6 @@ func (l *StateLocalizer) LoadTranslations(filePath string) error {
 			if trans.Messages == nil {
 				trans.Messages = make(map[string]map[string]string)
 			}
-			err := trans.isLocalized(supportedLanguages)
-			if err != nil {
-				locErr := NewAppError(ErrLocalization, "localization_file_translations_error", map[string]any{
-					"file":  filePath,
-					"path":  fmt.Sprintf("translations.%v.%s", scope, stateId),
-					"error": err,
-				})
-				if l.lm.isCoreLocalization(filePath) {
-					return locErr
-				}
-				l.lm.logError(locErr)
-			}
 			l.Translations[scope][stateId] = trans
 		}
 	}
@@ -135,3 +150,23 @@ func (l *StateLocalizer) GetMessage(scope Scope, stateId, messageKey string) (st
 	}
 	return fetchTranslation(l.lm, message)
 }
+
+// GetTitle возвращает название состояния; если оно не задано, ok == false.
+func (l *StateLocalizer) GetTitle(scope Scope, stateId string) (string, bool) {
+	title := l.Translations[scope][stateId].Title
+	if len(title) == 0 {
+		return "", false
+	}
+	msg, err := fetchTranslation(l.lm, title)
+	return msg, err == nil
+}
+
+// GetAccessibleMessage возвращает доступный вариант сообщения; если варианта нет, ok == false и нужно обычное сообщение.
+func (l *StateLocalizer) GetAccessibleMessage(scope Scope, stateId, messageKey string) (string, bool) {
+	message, exists := l.Translations[scope][stateId].Accessible[messageKey]
+	if !exists {
+		return "", false
+	}
+	msg, err := fetchTranslation(l.lm, message)
+	return msg, err == nil
+}
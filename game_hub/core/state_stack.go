@@ -1,19 +1,49 @@
 package core
 
+// MaxStateHistory ограничивает глубину стека: при переполнении забываются самые старые состояния после корневого.
+const MaxStateHistory = 32
+
 type StateStack struct {
 	states []State
+	limit  int
 }
 
 func NewStateStack() *StateStack {
-	return &StateStack{states: make([]State, 0)}
+	return &StateStack{
+		states: make([]State, 0),
+		limit:  MaxStateHistory,
+	}
 }
 
 func (s *StateStack) IsEmpty() bool {
 	return len(s.states) == 0
 }
 
-func (s *StateStack) Push(state State) {
+func (s *StateStack) Len() int {
+	return len(s.states)
+}
+
+// Push возвращает состояние, вытесненное из истории при переполнении, или nil.
+// Загрузка игры не вытесняется никогда: её OnExit закрыл бы игру, которая ещё идёт.
+func (s *StateStack) Push(state State) State {
 	s.states = append(s.states, state)
+	if s.limit <= 1 || len(s.states) <= s.limit {
+		return nil
+	}
+	for i := 1; i < len(s.states)-1; i++ {
+		if isGameInit(s.states[i]) {
+			continue
+		}
+		dropped := s.states[i]
+		s.states = append(s.states[:i], s.states[i+1:]...)
+		return dropped
+	}
+	return nil
+}
+
+func isGameInit(state State) bool {
+	_, ok := state.(*InitGameState)
+	return ok
 }
 
 func (s *StateStack) Pop() State {
@@ -34,6 +64,37 @@ func (s *StateStack) Peek() State {
 	return s.states[lastIndex]
 }
 
+// Truncate оставляет в стеке только первые size состояний.
+func (s *StateStack) Truncate(size int) {
+	if size < len(s.states) {
+		s.states = s.states[:size]
+	}
+}
+
+// LastIndex возвращает позицию ближайшего к вершине состояния, удовлетворяющего условию, или -1.
+func (s *StateStack) LastIndex(match func(State) bool) int {
+	for i := len(s.states) - 1; i >= 0; i-- {
+		if match(s.states[i]) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Ids возвращает идентификаторы состояний от корня к вершине.
+func (s *StateStack) Ids() []string {
+	ids := make([]string, len(s.states))
+	for i, state := range s.states {
+		ids[i] = state.Id()
+	}
+	return ids
+}
+
+// States возвращает копию стека от корня к вершине.
+func (s *StateStack) States() []State {
+	return append([]State(nil), s.states...)
+}
+
 func (s *StateStack) Clear() {
 	s.states = s.states[:0]
 }
@@ -50,22 +50,38 @@ func (g *InitGameState) Init(ctx *AppContext, ui *UiContext) (State, error) {
 			"error": err,
 		})
 	}
+	if err := ui.CommandRegistry.RegisterCommands(LayerGame, gameCommands(g.Game)); err != nil {
+		return &GameExitState{}, err
+	}
 	return g, nil
 }
 
+// Title называет состояние загрузки именем игры: с него в цепочке начинаются состояния игры.
+func (g *InitGameState) Title(ctx *AppContext, ui *UiContext) string {
+	return ui.GetOptionalLocalizedMsg(ui.AppLocalizer, g.Game.GetId(), "name")
+}
+
 func (g *InitGameState) Display(ctx *AppContext, ui *UiContext) {
 	ui.DisplayText(fmt.Sprintf(ui.GetLocalizedStateMsg(g, "game_welcome"), ui.GetOptionalLocalizedMsg(ui.AppLocalizer, g.Game.GetId(), "name")) + "\r\n")
 }
 
-func (g *InitGameState) Handle(ctx *AppContext, ui *UiContext, input string) (State, error) {
+// Handle оставляет состояние инициализации в стеке: к нему ведёт переход ResetToGameStart.
+func (g *InitGameState) Handle(ctx *AppContext, ui *UiContext, input string) (Transition, error) {
 	ctx.Game = g.Game.CreateNew()
-	return g.Game.GetStartState(), nil
+	// испорченное сохранение не мешает начать игру с настройками по умолчанию
+	err := RestoreGame(ctx, ctx.Game)
+	return Push(ctx.Game.GetStartState()), err
 }
 
 func (g *InitGameState) RequiresInput() bool {
 	return false
 }
 
+// OnExit срабатывает при любом выходе из игры: через меню, по команде или при завершении программы.
+func (g *InitGameState) OnExit(ctx *AppContext, ui *UiContext) error {
+	return ctx.LeaveGame(ui)
+}
+
 type ExitState struct{ BaseState }
 
 func (e *ExitState) Id() string {
@@ -76,9 +92,8 @@ func (e *ExitState) Display(_ *AppContext, ui *UiContext) {
 	ui.DisplayText(ui.GetLocalizedStateMsg(e, "exit") + "\r\n")
 }
 
-func (e *ExitState) Handle(ctx *AppContext, _ *UiContext, _ string) (State, error) {
-	ctx.AppIsRunning = false
-	return e, nil
+func (e *ExitState) Handle(_ *AppContext, _ *UiContext, _ string) (Transition, error) {
+	return Exit(), nil
 }
 
 func (e *ExitState) RequiresInput() bool {
@@ -91,10 +106,9 @@ func (e *GameExitState) Id() string {
 	return "game_exit"
 }
 
-func (e *GameExitState) Handle(ctx *AppContext, ui *UiContext, _ string) (State, error) {
-	ui.GameLocalizer = NewMessageLocalizer(ui.LocalizationManager)
-	ctx.GoToMenu = true
-	return e, nil
+// Handle закрывает игру сам, потому что при ошибке загрузки InitGameState не попадает в стек.
+func (e *GameExitState) Handle(ctx *AppContext, ui *UiContext, _ string) (Transition, error) {
+	return PopToRoot(), ctx.LeaveGame(ui)
 }
 
 func (e *GameExitState) RequiresInput() bool {
@@ -125,9 +139,9 @@ func (d *ConfirmationDialogState) Display(_ *AppContext, ui *UiContext) {
 	ui.DisplayText(fmt.Sprintf("%s\r\n", ui.GetLocalizedMsg(ui.AppLocalizer, d.message)))
 }
 
-func (d *ConfirmationDialogState) Handle(ctx *AppContext, ui *UiContext, input string) (State, error) {
+func (d *ConfirmationDialogState) Handle(ctx *AppContext, ui *UiContext, input string) (Transition, error) {
 	ui.DisplayText(ui.GetLocalizedStateMsg(d, "confirmation_prompt") + "\r\n")
-	return d, nil
+	return Stay(), nil
 }
 
 func (d *ConfirmationDialogState) GetCommands() []Command {
@@ -141,7 +155,7 @@ type MenuOption struct {
 	Id          int
 	Description string
 	Params      func() map[string]any
-	NextState   func() State
+	Next        func() Transition
 }
 
 type MenuState struct {
@@ -150,6 +164,8 @@ type MenuState struct {
 	Options     []MenuOption
 	OptionsMap  map[int]MenuOption
 	Greeting    string
+	// selected — индекс пункта, подсвеченного в посимвольном режиме
+	selected int
 }
 
 func NewMenu(parentState State, options []MenuOption, greeting string) *MenuState {
@@ -160,11 +176,17 @@ func NewMenu(parentState State, options []MenuOption, greeting string) *MenuStat
 	for _, option := range options {
 		optionsMap[option.Id] = option
 	}
+	// по умолчанию подсвечен первый пункт после выхода, чтобы Enter не закрывал меню случайно
+	selected := 0
+	if len(options) > 1 && options[0].Id == 0 {
+		selected = 1
+	}
 	return &MenuState{
 		ParentState: parentState,
 		Options:     options,
 		OptionsMap:  optionsMap,
 		Greeting:    greeting,
+		selected:    selected,
 	}
 }
 
@@ -172,29 +194,107 @@ func (m *MenuState) Id() string {
 	return "menu"
 }
 
+// Title берёт название у состояния, которому принадлежит меню.
+func (m *MenuState) Title(ctx *AppContext, ui *UiContext) string {
+	if m.ParentState == nil {
+		return ""
+	}
+	return ui.GetLocalizedStateTitle(ctx, m.ParentState)
+}
+
 func (m *MenuState) Display(ctx *AppContext, ui *UiContext) {
 	m.ShowGreeting(ctx, ui)
+	if ui.Accessible() {
+		ui.DisplayText(fmt.Sprintf(ui.GetLocalizedStateMsg(m, "options_count")+"\r\n", len(m.Options)))
+	}
 	for _, option := range m.Options {
-		desc := ui.GetLocalizedStateMsg(m.ParentState, option.Description)
-		if option.Params != nil {
-			desc = utils.SubstituteParams(desc, option.Params())
-		}
-		ui.DisplayText(fmt.Sprintf("%d. %s\r\n", option.Id, desc))
+		ui.DisplayText(m.optionText(ui, option) + "\r\n")
 	}
-	ui.DisplayText(ui.GetLocalizedStateMsg(m, "make_your_choice") + "\r\n")
+	if _, keyMode := ui.KeyReader(); keyMode {
+		ui.DisplayText(ui.GetLocalizedStateMsg(m, "make_your_choice_keys") + "\r\n")
+		return
+	}
+	ui.DisplayText(utils.SubstituteParams(ui.GetLocalizedStateMsg(m, "make_your_choice"), m.rangeParams()) + "\r\n")
 }
 
-func (m *MenuState) Handle(ctx *AppContext, ui *UiContext, input string) (State, error) {
+// rangeParams — наименьший и наибольший номера пунктов для подсказки, что именно нужно ввести.
+func (m *MenuState) rangeParams() map[string]any {
+	if len(m.Options) == 0 {
+		return nil
+	}
+	return map[string]any{
+		"first": m.Options[0].Id,
+		"last":  m.Options[len(m.Options)-1].Id,
+	}
+}
+
+func (m *MenuState) Handle(ctx *AppContext, ui *UiContext, input string) (Transition, error) {
 	num, err := ui.Validator.ParseInt(input)
 	if err != nil {
-		return m, err
+		return Stay(), err
 	}
 	option, exists := m.OptionsMap[num]
 	if !exists {
 		ui.DisplayText(ui.GetLocalizedStateMsg(m, "invalid_option") + "\r\n")
-		return m, nil
+		return Stay(), nil
+	}
+	return option.Next(), nil
+}
+
+func (m *MenuState) optionText(ui *UiContext, option MenuOption) string {
+	desc := ui.GetLocalizedStateMsg(m.ParentState, option.Description)
+	if option.Params != nil {
+		desc = utils.SubstituteParams(desc, option.Params())
+	}
+	return fmt.Sprintf("%s %s", Style(StyleOption, fmt.Sprintf("%d.", option.Id)), desc)
+}
+
+// selectedMarker отмечает выбранный пункт меню, когда выделить его стилем нельзя
+const selectedMarker = "* "
+
+// KeyPrompt показывает подсвеченный пункт прямо в строке ввода: с цветами его выделяет стиль темы, без них — маркер.
+func (m *MenuState) KeyPrompt(ctx *AppContext, ui *UiContext) string {
+	if len(m.Options) == 0 {
+		return "> "
+	}
+	// собственная разметка пункта убирается, чтобы не смешиваться с выделением
+	text := ui.Theme.Render(m.optionText(ui, m.Options[m.selected]), false)
+	if !ui.colorsEnabled() {
+		return "> " + selectedMarker + text
+	}
+	return "> " + ui.Theme.Render(Style(StyleSelected, text), true)
+}
+
+// HandleKey перемещает подсветку стрелками и выбирает пункт по Enter или по цифре его номера.
+func (m *MenuState) HandleKey(ctx *AppContext, ui *UiContext, key Key) (Transition, KeyResult, error) {
+	if len(m.Options) == 0 {
+		return Stay(), KeyIgnored, nil
+	}
+	switch key.Code {
+	case KeyUp:
+		m.selected = (m.selected + len(m.Options) - 1) % len(m.Options)
+		return Stay(), KeyConsumed, nil
+	case KeyDown:
+		m.selected = (m.selected + 1) % len(m.Options)
+		return Stay(), KeyConsumed, nil
+	case KeyHome:
+		m.selected = 0
+		return Stay(), KeyConsumed, nil
+	case KeyEnd:
+		m.selected = len(m.Options) - 1
+		return Stay(), KeyConsumed, nil
+	case KeyEnter:
+		return m.Options[m.selected].Next(), KeyHandled, nil
+	}
+	digit, ok := key.Digit()
+	if !ok {
+		return Stay(), KeyIgnored, nil
+	}
+	if option, exists := m.OptionsMap[digit]; exists {
+		return option.Next(), KeyHandled, nil
 	}
-	return option.NextState(), nil
+	// несуществующий номер просто игнорируем, меню остаётся на экране
+	return Stay(), KeyConsumed, nil
 }
 
 func (m *MenuState) ShowGreeting(ctx *AppContext, ui *UiContext) {
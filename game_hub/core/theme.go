@@ -0,0 +1,165 @@
+package core
+
+import (
+	"game_hub/config"
+	"os"
+	"strings"
+)
+
+// Смысловые стили разметки. В тексте стиль задаётся парой тегов: "{win}Вы выиграли!{/win}".
+// StyleDecor отмечает псевдографику и украшения, которые в режиме доступности не выводятся.
+const (
+	StyleBold      = "b"
+	StyleItalic    = "i"
+	StyleUnderline = "u"
+	StyleDim       = "dim"
+	StyleTitle     = "title"
+	StyleOption    = "option"
+	StyleHint      = "hint"
+	StyleWin       = "win"
+	StyleLoss      = "loss"
+	StyleDraw      = "draw"
+	StyleError     = "error"
+	StyleWarning   = "warning"
+	StyleDecor     = "decor"
+	StyleSelected  = "selected"
+)
+
+// markupStyles — теги, которые распознаются и убираются из текста, даже если тема их не оформляет
+var markupStyles = []string{
+	StyleBold, StyleItalic, StyleUnderline, StyleDim, StyleTitle, StyleOption,
+	StyleHint, StyleWin, StyleLoss, StyleDraw, StyleError, StyleWarning, StyleDecor, StyleSelected,
+}
+
+// styleAttributes переводит названия атрибутов из файла темы в параметры ANSI SGR.
+var styleAttributes = map[string]string{
+	"bold": "1", "dim": "2", "italic": "3", "underline": "4", "reverse": "7",
+	"black": "30", "red": "31", "green": "32", "yellow": "33",
+	"blue": "34", "magenta": "35", "cyan": "36", "white": "37",
+	"bright_black": "90", "bright_red": "91", "bright_green": "92", "bright_yellow": "93",
+	"bright_blue": "94", "bright_magenta": "95", "bright_cyan": "96", "bright_white": "97",
+	"bg_black": "40", "bg_red": "41", "bg_green": "42", "bg_yellow": "43",
+	"bg_blue": "44", "bg_magenta": "45", "bg_cyan": "46", "bg_white": "47",
+}
+
+const resetSGR = "\x1b[0m"
+
+type ThemeData struct {
+	Meta   SchemaMetadata      `json:"meta"`
+	Styles map[string][]string `json:"styles" validate:"required,dive,dive,oneof=bold dim italic underline reverse black red green yellow blue magenta cyan white bright_black bright_red bright_green bright_yellow bright_blue bright_magenta bright_cyan bright_white bg_black bg_red bg_green bg_yellow bg_blue bg_magenta bg_cyan bg_white"`
+}
+
+// Theme сопоставляет стилям разметки escape-последовательности терминала.
+type Theme struct {
+	styles map[string]string
+}
+
+// NewTheme собирает тему из стилей файла темы: каждый стиль — список атрибутов, например ["bold", "green"].
+func NewTheme(styles map[string][]string) *Theme {
+	theme := &Theme{styles: make(map[string]string, len(styles))}
+	for name, attributes := range styles {
+		codes := make([]string, 0, len(attributes))
+		for _, attribute := range attributes {
+			if code, ok := styleAttributes[attribute]; ok {
+				codes = append(codes, code)
+			}
+		}
+		if len(codes) > 0 {
+			theme.styles[name] = "\x1b[" + strings.Join(codes, ";") + "m"
+		}
+	}
+	return theme
+}
+
+// LoadTheme читает файл темы; если его нет, возвращается пустая тема, и разметка просто убирается из текста.
+func LoadTheme(cfg *config.Config) (*Theme, error) {
+	filePath := cfg.Paths.CoreThemePath()
+	root, err := loadUserData(cfg, filePath)
+	if root == nil || err != nil {
+		return NewTheme(nil), err
+	}
+	var data ThemeData
+	if err := DecodeNode(filePath, root, &data); err != nil {
+		return NewTheme(nil), err
+	}
+	return NewTheme(data.Styles), nil
+}
+
+// Style оборачивает текст в теги стиля.
+func Style(name, text string) string {
+	return "{" + name + "}" + text + "{/" + name + "}"
+}
+
+// ColorsAllowed сообщает, не отключены ли цвета переменной окружения NO_COLOR (см. no-color.org).
+func ColorsAllowed() bool {
+	return os.Getenv("NO_COLOR") == ""
+}
+
+// Render заменяет теги разметки escape-последовательностями темы или, при colors == false, просто убирает их.
+// Незнакомые теги и остальные фигурные скобки остаются в тексте как есть.
+func (t *Theme) Render(text string, colors bool) string {
+	if t == nil {
+		t = &Theme{}
+	}
+	if !strings.Contains(text, "{") {
+		return text
+	}
+	var b strings.Builder
+	// открытые стили; после закрывающего тега оформление сбрасывается и восстанавливается для оставшихся
+	open := make([]string, 0, 2)
+	for {
+		start := strings.IndexByte(text, '{')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(text[start:], '}')
+		if end < 0 {
+			break
+		}
+		tag := text[start+1 : start+end]
+		name, closing := strings.CutPrefix(tag, "/")
+		if !t.isStyle(name) {
+			b.WriteString(text[:start+1])
+			text = text[start+1:]
+			continue
+		}
+		b.WriteString(text[:start])
+		text = text[start+end+1:]
+		if !closing {
+			open = append(open, name)
+			if colors {
+				b.WriteString(t.styles[name])
+			}
+			continue
+		}
+		for i := len(open) - 1; i >= 0; i-- {
+			if open[i] == name {
+				open = append(open[:i], open[i+1:]...)
+				break
+			}
+		}
+		if colors {
+			b.WriteString(resetSGR)
+			for _, style := range open {
+				b.WriteString(t.styles[style])
+			}
+		}
+	}
+	b.WriteString(text)
+	if colors && len(open) > 0 {
+		b.WriteString(resetSGR)
+	}
+	return b.String()
+}
+
+func (t *Theme) isStyle(name string) bool {
+	if _, ok := t.styles[name]; ok {
+		return true
+	}
+	for _, style := range markupStyles {
+		if style == name {
+			return true
+		}
+	}
+	return false
+}
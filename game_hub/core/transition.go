@@ -0,0 +1,75 @@
+package core
+
+type TransitionKind int
+
+const (
+	// остаться в текущем состоянии
+	TransitionStay TransitionKind = iota
+	// открыть новое состояние поверх текущего, чтобы к нему можно было вернуться
+	TransitionPush
+	// заменить текущее состояние, не увеличивая историю
+	TransitionReplace
+	// вернуться к предыдущему состоянию
+	TransitionPop
+	// вернуться к корневому состоянию, например к главному меню хаба
+	TransitionPopToRoot
+	// вернуться к начальному состоянию текущей игры
+	TransitionResetToGameStart
+	// завершить работу движка
+	TransitionExit
+)
+
+func (k TransitionKind) String() string {
+	switch k {
+	case TransitionStay:
+		return "stay"
+	case TransitionPush:
+		return "push"
+	case TransitionReplace:
+		return "replace"
+	case TransitionPop:
+		return "pop"
+	case TransitionPopToRoot:
+		return "pop_to_root"
+	case TransitionResetToGameStart:
+		return "reset_to_game_start"
+	case TransitionExit:
+		return "exit"
+	default:
+		return "unknown"
+	}
+}
+
+// Transition описывает, как обработчик состояния или команды хочет изменить стек состояний.
+type Transition struct {
+	Kind  TransitionKind
+	State State
+}
+
+func Stay() Transition {
+	return Transition{Kind: TransitionStay}
+}
+
+func Push(state State) Transition {
+	return Transition{Kind: TransitionPush, State: state}
+}
+
+func Replace(state State) Transition {
+	return Transition{Kind: TransitionReplace, State: state}
+}
+
+func Pop() Transition {
+	return Transition{Kind: TransitionPop}
+}
+
+func PopToRoot() Transition {
+	return Transition{Kind: TransitionPopToRoot}
+}
+
+func ResetToGameStart() Transition {
+	return Transition{Kind: TransitionResetToGameStart}
+}
+
+func Exit() Transition {
+	return Transition{Kind: TransitionExit}
+}
@@ -0,0 +1,243 @@
+package core
+
+import (
+	"regexp"
+	"strings"
+)
+
+// TranslationUnit — набор переводов одного ключа вида {"<lang>": ...} и путь к нему внутри "translations".
+type TranslationUnit struct {
+	Path []string
+	Node *DataNode
+}
+
+func (u TranslationUnit) Context() string {
+	return strings.Join(u.Path, "/")
+}
+
+// loadDataNode читает файл данных и обновляет его схему в памяти, не трогая файл на диске.
+func (lm *LocalizationManager) loadDataNode(filePath string) (*DataNode, error) {
+	data, err := ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	root, err := ParseDataFile(filePath, data)
+	if err != nil {
+		return nil, NewAppError(ErrLocalization, "file_parse_error", map[string]any{
+			"file":  filePath,
+			"error": err,
+		})
+	}
+	if _, err := MigrateNode(filePath, lm.cfg.Paths.DataFileKind(filePath), root); err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+func (lm *LocalizationManager) TranslationUnits(root *DataNode) []TranslationUnit {
+	units := make([]TranslationUnit, 0)
+	lm.collectTranslationUnits(root.Get("translations"), []string{}, &units)
+	return units
+}
+
+func (lm *LocalizationManager) collectTranslationUnits(node *DataNode, path []string, units *[]TranslationUnit) {
+	if node == nil || node.Kind != NodeObject {
+		return
+	}
+	if lm.isTranslationUnit(node) {
+		*units = append(*units, TranslationUnit{Path: path, Node: node})
+		return
+	}
+	for _, key := range node.Keys {
+		childPath := append(append(make([]string, 0, len(path)+1), path...), key)
+		lm.collectTranslationUnits(node.Fields[key], childPath, units)
+	}
+}
+
+func (lm *LocalizationManager) isTranslationUnit(node *DataNode) bool {
+	if len(node.Keys) == 0 {
+		return false
+	}
+	for _, key := range node.Keys {
+		if !lm.isLanguageExists(key) {
+			return false
+		}
+		switch node.Fields[key].Kind {
+		case NodeString, NodeArray:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+var (
+	// printfPattern находит глаголы fmt, которые встречаются в переводах.
+	// Знак "%" перед пробелом или буквой, как в "$percent% translated", — обычный текст.
+	printfPattern      = regexp.MustCompile(`%[-+#0]*\d*(\.\d+)?[vdsfgqxXtT%]`)
+	placeholderPattern = regexp.MustCompile(printfPattern.String() + `|\$[a-zA-Z_]+`)
+)
+
+// isCFormat сообщает, есть ли в тексте настоящий глагол printf, кроме "%%".
+// Только тогда msgfmt -c должен сверять глаголы в переводе.
+func isCFormat(text string) bool {
+	for _, verb := range printfPattern.FindAllString(text, -1) {
+		if verb != "%%" {
+			return true
+		}
+	}
+	return false
+}
+
+// poListSeparator разделяет элементы списка, например псевдонимы команды, в записи PO.
+// Запятая для этого не годится: она может встретиться внутри элемента.
+const poListSeparator = "\n"
+
+func unitText(node *DataNode) string {
+	if node.Kind == NodeArray {
+		return strings.Join(node.Strings(), poListSeparator)
+	}
+	return node.Value
+}
+
+func unitNode(source *DataNode, text string) *DataNode {
+	if source == nil || source.Kind != NodeArray {
+		return NewStringNode(text)
+	}
+	items := make([]*DataNode, 0)
+	for _, item := range strings.Split(text, poListSeparator) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, NewStringNode(item))
+		}
+	}
+	return NewArrayNode(items)
+}
+
+// ExportPo формирует файл gettext для одного файла данных; при пустом lang получается шаблон (.pot).
+func (lm *LocalizationManager) ExportPo(filePath, reference, lang string) (*PoFile, error) {
+	if lang != "" && !lm.isLanguageExists(lang) {
+		return nil, NewAppError(ErrLocalization, "localization_metadata_invalid_language", map[string]any{"lang": lang})
+	}
+	root, err := lm.loadDataNode(filePath)
+	if err != nil {
+		return nil, err
+	}
+	po := NewPoFile()
+	po.Header["Project-Id-Version"] = AppName + " " + Version
+	po.Header["Language"] = lang
+	po.Header["MIME-Version"] = "1.0"
+	po.Header["Content-Type"] = "text/plain; charset=UTF-8"
+	po.Header["Content-Transfer-Encoding"] = "8bit"
+	po.Header["X-Source-Language"] = lm.defaultLang
+	po.Header["X-Source-File"] = reference
+	for _, unit := range lm.TranslationUnits(root) {
+		source := unit.Node.Get(lm.defaultLang)
+		if source == nil || unitText(source) == "" {
+			continue
+		}
+		entry := PoEntry{
+			Context:   unit.Context(),
+			Reference: reference,
+			Id:        unitText(source),
+		}
+		if source.Kind == NodeArray {
+			entry.Comments = append(entry.Comments, "list: one item per line")
+		}
+		if placeholders := placeholderPattern.FindAllString(source.Value, -1); len(placeholders) > 0 {
+			entry.Comments = append(entry.Comments, "placeholders: "+strings.Join(placeholders, ", "))
+			if isCFormat(source.Value) {
+				entry.Flags = append(entry.Flags, "c-format")
+			}
+		}
+		if translation := unit.Node.Get(lang); lang != "" && translation != nil {
+			entry.Str = unitText(translation)
+		}
+		po.Entries = append(po.Entries, entry)
+	}
+	return po, nil
+}
+
+type PoImportResult struct {
+	Updated  int
+	Skipped  int
+	Complete bool
+}
+
+// ImportPo записывает переводы из файла gettext обратно в файл данных, сохраняя порядок ключей.
+// Ключи ищутся в дереве с обновлённой схемой, а в файл попадают только переведённые значения:
+// остальное содержимое файла, в том числе версия его схемы, остаётся как было.
+func (lm *LocalizationManager) ImportPo(filePath string, po *PoFile, lang string) (PoImportResult, error) {
+	var result PoImportResult
+	if !lm.isLanguageExists(lang) {
+		return result, NewAppError(ErrLocalization, "localization_metadata_invalid_language", map[string]any{"lang": lang})
+	}
+	root, err := lm.loadDataNode(filePath)
+	if err != nil {
+		return result, err
+	}
+	data, err := ReadFile(filePath)
+	if err != nil {
+		return result, err
+	}
+	fileRoot, err := ParseDataFile(filePath, data)
+	if err != nil {
+		return result, NewAppError(ErrLocalization, "file_parse_error", map[string]any{
+			"file":  filePath,
+			"error": err,
+		})
+	}
+	units := lm.TranslationUnits(root)
+	unitsMap := make(map[string]TranslationUnit, len(units))
+	for _, unit := range units {
+		unitsMap[unit.Context()] = unit
+	}
+	for _, entry := range po.Entries {
+		unit, exists := unitsMap[entry.Context]
+		if !exists || entry.Str == "" || entry.isFuzzy() {
+			result.Skipped++
+			continue
+		}
+		fileUnit := fileRoot.Find(append([]string{"translations"}, unit.Path...))
+		if fileUnit == nil || fileUnit.Kind != NodeObject {
+			result.Skipped++
+			continue
+		}
+		value := unitNode(unit.Node.Get(lm.defaultLang), entry.Str)
+		unit.Node.Set(lang, value)
+		fileUnit.Set(lang, value)
+		result.Updated++
+	}
+	result.Complete = true
+	for _, unit := range units {
+		if unit.Node.Get(lm.defaultLang) != nil && unit.Node.Get(lang) == nil {
+			result.Complete = false
+			break
+		}
+	}
+	languageAdded := result.Complete && addSupportedLanguage(fileRoot, lang)
+	if result.Updated == 0 && !languageAdded {
+		return result, nil
+	}
+	updatedData, err := EncodeDataFile(filePath, fileRoot)
+	if err != nil {
+		return result, err
+	}
+	if err := WriteFile(filePath, updatedData); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+func addSupportedLanguage(root *DataNode, lang string) bool {
+	langs := root.Get("meta").Get("supported_languages")
+	if langs == nil || langs.Kind != NodeArray {
+		return false
+	}
+	for _, code := range langs.Strings() {
+		if code == lang {
+			return false
+		}
+	}
+	langs.Items = append(langs.Items, NewStringNode(lang))
+	return true
+}
@@ -2,10 +2,13 @@ package core
 
 import (
 	"fmt"
-	"game_hub/utils"
+	"game_hub/config"
 	"strings"
 )
 
+// defaultTextWidth — ширина переноса, когда ширина терминала неизвестна, например при выводе в файл
+const defaultTextWidth = 80
+
 type UiContext struct {
 	Console             Console
 	Validator           *InputValidator
@@ -16,29 +19,92 @@ type UiContext struct {
 	AppLocalizer        *MessageLocalizer
 	GameLocalizer       *MessageLocalizer
 	StateLocalizer      *StateLocalizer
+	// WrapWidth — ширина переноса текста: config.WrapAuto берёт её у консоли, 0 отключает перенос
+	WrapWidth int
+	// Theme оформляет разметку вроде {win}...{/win}; без темы разметка просто убирается
+	Theme *Theme
+	// Policy решает, как выводится текст; без неё используется VisualPolicy
+	Policy RenderPolicy
 }
 
+// DisplayText выводит текст так, как велит политика вывода.
 func (ui *UiContext) DisplayText(txt string) {
-	if err := ui.Console.Write(utils.WrapText(txt, 80)); err != nil {
+	if err := ui.Console.Write(ui.policy().Format(ui, txt)); err != nil {
 		fmt.Println(ui.ErrorHandler.Handle(err))
 	}
 }
 
+func (ui *UiContext) policy() RenderPolicy {
+	if ui.Policy == nil {
+		return VisualPolicy{}
+	}
+	return ui.Policy
+}
+
+// Accessible сообщает, включён ли режим доступности для программ экранного доступа.
+func (ui *UiContext) Accessible() bool {
+	return ui.policy().Accessible()
+}
+
+// SetAccessible переключает режим доступности.
+func (ui *UiContext) SetAccessible(enabled bool) {
+	if enabled {
+		ui.Policy = AccessiblePolicy{}
+	} else {
+		ui.Policy = VisualPolicy{}
+	}
+}
+
+// textWidth спрашивает ширину у консоли при каждом выводе, поэтому после изменения размера окна текст переносится по-новому.
+func (ui *UiContext) textWidth() int {
+	if ui.WrapWidth != config.WrapAuto {
+		return ui.WrapWidth
+	}
+	if sized, ok := ui.Console.(TextWidthProvider); ok {
+		return sized.TextWidth()
+	}
+	return defaultTextWidth
+}
+
+// colorsEnabled разрешает цвета, только если консоль выводит в терминал и они не отключены через NO_COLOR.
+func (ui *UiContext) colorsEnabled() bool {
+	colored, ok := ui.Console.(ColorSupport)
+	return ok && colored.SupportsColor() && ColorsAllowed()
+}
+
+// KeyReader возвращает консоль с посимвольным вводом, если она его поддерживает и подключена к терминалу.
+// В режиме доступности ввод всегда построчный: подсветку пункта в строке ввода программы экранного доступа не озвучивают.
+func (ui *UiContext) KeyReader() (KeyReader, bool) {
+	keys, ok := ui.Console.(KeyReader)
+	if !ok || !keys.SupportsKeys() || ui.Accessible() {
+		return nil, false
+	}
+	return keys, true
+}
+
+// DisplayError показывает игроку ошибку, с которой он может что-то сделать: неверный ввод, сбой сохранения и т. п.
+// Неполадки в данных и прочие ошибки для разработчиков пишутся в журнал.
 func (ui *UiContext) DisplayError(err error) {
 	msg := ui.ErrorHandler.Handle(err)
 	if msg != "" {
-		ui.DisplayText(fmt.Sprintf("%s\r\n", msg))
+		ui.DisplayText(Style(StyleError, msg) + "\r\n")
 	}
 }
 
-func (ui *UiContext) HandleInput(input string, ctx *AppContext) (State, error) {
+func (ui *UiContext) HandleInput(input string, ctx *AppContext) (Transition, error) {
 	input = strings.TrimSpace(input)
-	if cmd, args := ui.CommandRegistry.ParseInput(input); cmd != nil {
-		return cmd.Execute(ctx, ui, args)
-	}
 	state, err := ctx.GetCurrentState()
+	if cmd, args := ui.CommandRegistry.ParseInput(input, ctx); cmd != nil {
+		return ui.CommandRegistry.Dispatch(&CommandCall{
+			Command: cmd,
+			Args:    args,
+			State:   state,
+			Ctx:     ctx,
+			UI:      ui,
+		})
+	}
 	if err != nil {
-		return nil, err
+		return Stay(), err
 	}
 	return state.Handle(ctx, ui, input)
 }
@@ -46,7 +112,7 @@ func (ui *UiContext) HandleInput(input string, ctx *AppContext) (State, error) {
 func (ui *UiContext) GetLocalizedMsg(localizer *MessageLocalizer, key string) string {
 	msg, err := localizer.Get(key)
 	if err != nil {
-		ui.DisplayError(err)
+		ui.logError(err)
 	}
 	return msg
 }
@@ -54,7 +120,7 @@ func (ui *UiContext) GetLocalizedMsg(localizer *MessageLocalizer, key string) st
 func (ui *UiContext) GetOptionalLocalizedMsg(localizer *MessageLocalizer, set string, key string) string {
 	msg, err := localizer.GetOptional(set, key)
 	if err != nil {
-		ui.DisplayError(err)
+		ui.logError(err)
 	}
 	return msg
 }
@@ -62,7 +128,7 @@ func (ui *UiContext) GetOptionalLocalizedMsg(localizer *MessageLocalizer, set st
 func (ui *UiContext) GetLocalizedCmdName(cmd Command) string {
 	name, err := ui.CommandRegistry.GetName(cmd)
 	if err != nil {
-		ui.DisplayError(err)
+		ui.logError(err)
 	}
 	return name
 }
@@ -70,7 +136,7 @@ func (ui *UiContext) GetLocalizedCmdName(cmd Command) string {
 func (ui *UiContext) GetLocalizedCmdDescription(cmd Command) string {
 	desc, err := ui.CommandRegistry.GetDescription(cmd)
 	if err != nil {
-		ui.DisplayError(err)
+		ui.logError(err)
 	}
 	return desc
 }
@@ -78,23 +144,48 @@ func (ui *UiContext) GetLocalizedCmdDescription(cmd Command) string {
 func (ui *UiContext) GetLocalizedCmdAliases(cmd Command) []string {
 	aliases, err := ui.CommandRegistry.GetAliases(cmd)
 	if err != nil {
-		ui.DisplayError(err)
+		ui.logError(err)
 	}
 	return aliases
 }
 
+// GetLocalizedStateTitle возвращает название состояния для строки ввода и команды where или пустую строку, если его нет.
+func (ui *UiContext) GetLocalizedStateTitle(ctx *AppContext, state State) string {
+	if titled, ok := state.(TitledState); ok {
+		return titled.Title(ctx, ui)
+	}
+	title, _ := ui.StateLocalizer.GetTitle(state.Scope(), state.Id())
+	return title
+}
+
 func (ui *UiContext) GetLocalizedStateDescription(state State) string {
 	desc, err := ui.StateLocalizer.GetDescription(state.Scope(), state.Id())
 	if err != nil {
-		ui.DisplayError(err)
+		ui.logError(err)
 	}
 	return desc
 }
 
+// GetLocalizedStateMsg возвращает сообщение состояния; в режиме доступности — его доступный вариант, если он есть.
 func (ui *UiContext) GetLocalizedStateMsg(state State, key string) string {
+	if ui.Accessible() {
+		if msg, ok := ui.StateLocalizer.GetAccessibleMessage(state.Scope(), state.Id(), key); ok {
+			return msg
+		}
+	}
 	msg, err := ui.StateLocalizer.GetMessage(state.Scope(), state.Id(), key)
 	if err != nil {
-		ui.DisplayError(err)
+		ui.logError(err)
 	}
 	return msg
 }
+
+// logError записывает в журнал ошибку, которая игроку ничего не скажет, например отсутствующий перевод;
+// без журнала она всё же показывается, чтобы не потеряться.
+func (ui *UiContext) logError(err error) {
+	if ui.Logger == nil {
+		ui.DisplayError(err)
+		return
+	}
+	ui.Logger.Error(err)
+}
@@ -5,6 +5,8 @@ import (
 	"github.com/go-playground/validator/v10"
 	"io"
 	"os"
+	"path/filepath"
+	"reflect"
 )
 
 func EncodeData(data any) ([]byte, error) {
@@ -42,6 +44,50 @@ func ReadFile(filePath string) ([]byte, error) {
 	return data, nil
 }
 
+func WriteFile(filePath string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return NewAppError(Err, "file_write_error", map[string]any{
+			"file":  filePath,
+			"error": err,
+		})
+	}
+	if err := os.WriteFile(filePath, data, 0644); err != nil {
+		return NewAppError(Err, "file_write_error", map[string]any{
+			"file":  filePath,
+			"error": err,
+		})
+	}
+	return nil
+}
+
+// DecodeFile декодирует содержимое файла данных в формате, определяемом его расширением.
+func DecodeFile(filePath string, data []byte, target any) error {
+	node, err := ParseDataFile(filePath, data)
+	if err != nil {
+		return err
+	}
+	return DecodeNode(filePath, node, target)
+}
+
+// DecodeNode проверяет дерево целиком и возвращает все найденные проблемы с их позициями в файле.
+func DecodeNode(filePath string, node *DataNode, target any) error {
+	diagnostics := NewDataDiagnostics(filePath, node)
+	diagnostics.checkNodeType(node, reflect.TypeOf(target), []string{})
+	if err := diagnostics.Err(); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(node.Encode(), target); err != nil {
+		return NewAppError(Err, "decode_error", map[string]any{
+			"error": err,
+		})
+	}
+	if err := ValidateData(target); err != nil {
+		diagnostics.addValidationErrors(err)
+		return diagnostics.Err()
+	}
+	return nil
+}
+
 func DecodeData(data []byte, target any) error {
 	if err := json.Unmarshal(data, target); err != nil {
 		return NewAppError(Err, "decode_error", map[string]any{
@@ -49,12 +95,21 @@ func DecodeData(data []byte, target any) error {
 		})
 	}
 	if err := ValidateData(target); err != nil {
-		return NewAppError(Err, "decode_error", map[string]any{"error": err})
+		diagnostics := NewDataDiagnostics("", nil)
+		diagnostics.addValidationErrors(err)
+		return diagnostics.Err()
 	}
 	return nil
 }
 
-var validate *validator.Validate = validator.New(validator.WithRequiredStructEnabled())
+var validate *validator.Validate = newValidator()
+
+// newValidator называет поля так же, как они записаны в файлах данных, а не как в структурах Go.
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+	v.RegisterTagNameFunc(jsonFieldName)
+	return v
+}
 
 func ValidateData(data any) error {
 	err := validate.Struct(data)
@@ -69,11 +124,5 @@ func LoadData(filePath string, target any) error {
 	if err != nil {
 		return err
 	}
-	if err := DecodeData(data, target); err != nil {
-		return err
-	}
-	if err := ValidateData(target); err != nil {
-		return err
-	}
-	return nil
+	return DecodeFile(filePath, data, target)
 }
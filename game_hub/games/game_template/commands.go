@@ -10,7 +10,7 @@ func (c *CustomActionCommand) Id() string {
 	return "custom_action"
 }
 
-func (c *CustomActionCommand) Execute(ctx *core.AppContext, ui *core.UiContext, args []string) (core.State, error) {
+func (c *CustomActionCommand) Execute(ctx *core.AppContext, ui *core.UiContext, args []string) (core.Transition, error) {
 	// Реализуйте логику команды
-	return ctx.GetCurrentState()
+	return core.Stay(), nil
 }
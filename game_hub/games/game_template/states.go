@@ -24,8 +24,8 @@ func (b *BaseGameState) Init(ctx *core.AppContext, ui *core.UiContext) (core.Sta
 
 type StartState struct{ BaseGameState }
 
-func (s *StartState) Handle(ctx *core.AppContext, ui *core.UiContext, _ string) (core.State, error) {
-	return NewMainMenu(ctx, ui, s.game), nil
+func (s *StartState) Handle(ctx *core.AppContext, ui *core.UiContext, _ string) (core.Transition, error) {
+	return core.Replace(NewMainMenu(ctx, ui, s.game)), nil
 }
 
 func (s *StartState) RequiresInput() bool {
@@ -44,12 +44,12 @@ func NewMainMenu(ctx *core.AppContext, ui *core.UiContext, game *Game) *core.Men
 		{
 			Id:          0,
 			Description: "exit_option",
-			NextState:   func() core.State { return &core.GameExitState{} },
+			Next:        func() core.Transition { return core.Push(&core.GameExitState{}) },
 		},
 		{
 			Id:          1,
 			Description: "start_game",
-			NextState:   func() core.State { return &GameState{} },
+			Next:        func() core.Transition { return core.Push(&GameState{}) },
 		},
 	}
 	return core.NewMenu(parentState, options, "")
@@ -65,7 +65,7 @@ func (g *GameState) Display(ctx *core.AppContext, ui *core.UiContext) {
 	ui.DisplayText(ui.GetLocalizedStateMsg(g, "prompt") + "\r\n")
 }
 
-func (g *GameState) Handle(ctx *core.AppContext, ui *core.UiContext, input string) (core.State, error) {
+func (g *GameState) Handle(ctx *core.AppContext, ui *core.UiContext, input string) (core.Transition, error) {
 	// Реализуйте логику обработки ввода
-	return g, nil
+	return core.Stay(), nil
 }
@@ -10,6 +10,6 @@ func (c *RestartCommand) Id() string {
 	return "restart"
 }
 
-func (c *RestartCommand) Execute(ctx *core.AppContext, ui *core.UiContext, args []string) (core.State, error) {
-	return &StartGameState{}, nil
+func (c *RestartCommand) Execute(ctx *core.AppContext, ui *core.UiContext, args []string) (core.Transition, error) {
+	return core.Replace(&StartGameState{}), nil
 }
@@ -49,37 +49,67 @@ func (d Difficulty) GetModifier() int {
 	}
 }
 
+// Settings — выбранные игроком параметры, которые сохраняются между запусками
+type Settings struct {
+	Difficulty Difficulty `json:"difficulty" validate:"min=1,max=5"`
+	MinNumber  int        `json:"min_number"`
+	MaxNumber  int        `json:"max_number" validate:"gtfield=MinNumber"`
+	// время на каждую попытку в секундах, 0 — без ограничения
+	GuessTimeLimit int `json:"guess_time_limit" validate:"min=0"`
+}
+
+// Round — незаконченная партия: она попадает в сохранение, чтобы её можно было доиграть после перезапуска
+type Round struct {
+	SecretNumber int `json:"secret_number"`
+	Attempts     int `json:"attempts" validate:"min=1"`
+}
+
+// Snapshot — сохранение игры: настройки и партия, если игрок вышел посреди неё
+type Snapshot struct {
+	*Settings
+	Round *Round `json:"round,omitempty"`
+}
+
 type Game struct {
+	Settings
 	// минимальный диапазон угадывания чисел
 	MinRangeSize int
 	// минимально возможное число диапазона угадывания чисел
 	MinRangeNumber int
 	// максимально возможное число диапазона угадывания чисел
-	MaxRangeNumber  int
-	Difficulty      Difficulty
-	MinNumber       int
-	MaxNumber       int
+	MaxRangeNumber int
+	// максимальное время на попытку в секундах
+	MaxTimeLimit    int
 	secretNumber    int
 	attempts        int
 	isWon           bool
+	started         bool
 	RandomGenerator *core.RandomGenerator
+	// restored — сохранение, прочитанное при загрузке игры; партию из него берёт только Resume
+	restored Snapshot
 }
 
 func NewGame() *Game {
 	return &Game{
-		MinRangeSize:    20,
-		MinRangeNumber:  0,
-		MaxRangeNumber:  math.MaxInt32,
-		MinNumber:       1,
-		MaxNumber:       100,
-		Difficulty:      Medium,
+		MinRangeSize:   20,
+		MinRangeNumber: 0,
+		MaxRangeNumber: math.MaxInt32,
+		MaxTimeLimit:   60,
+		Settings: Settings{
+			Difficulty: Medium,
+			MinNumber:  1,
+			MaxNumber:  100,
+		},
 		isWon:           false,
 		RandomGenerator: core.NewRandomGenerator(),
 	}
 }
 
 func (g *Game) Prepare() error {
+	// новая партия отменяет сохранённую: к ней уже не вернуться, что бы ни сохранялось дальше
+	g.restored.Round = nil
 	g.isWon = false
+	g.started = true
 	secret, err := g.RandomGenerator.Generate(g.MinNumber, g.MaxNumber)
 	if err != nil {
 		return err
@@ -107,6 +137,29 @@ func (g *Game) CalculateAttempts() (int, error) {
 	return minAttempts + mod, nil
 }
 
+// InProgress сообщает, идёт ли партия: число загадано, но ещё не угадано, и попытки не кончились.
+func (g *Game) InProgress() bool {
+	return g.started && !g.isWon && g.attempts > 0
+}
+
+// Resume продолжает партию из восстановленного сохранения. Сохранённая партия продолжается только один раз,
+// а партия с числом вне диапазона из настроек считается испорченной и отбрасывается.
+func (g *Game) Resume() bool {
+	round := g.restored.Round
+	g.restored.Round = nil
+	if round == nil || round.SecretNumber < g.MinNumber || round.SecretNumber > g.MaxNumber {
+		return false
+	}
+	g.secretNumber, g.attempts = round.SecretNumber, round.Attempts
+	g.isWon, g.started = false, true
+	return true
+}
+
+// Abandon бросает текущую партию, чтобы она не попала в сохранение.
+func (g *Game) Abandon() {
+	g.started = false
+}
+
 func (g *Game) GetAttempts() int {
 	return g.attempts
 }
@@ -126,6 +179,11 @@ func (g *Game) MakeGuess(guess int) {
 	}
 }
 
+// MissGuess списывает попытку, на которую не хватило времени.
+func (g *Game) MissGuess() {
+	g.attempts--
+}
+
 func (g *Game) GetHint(guess int) string {
 	if guess < g.secretNumber {
 		return "hint_bigger"
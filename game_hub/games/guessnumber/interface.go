@@ -1,6 +1,7 @@
 package guessnumber
 
 import (
+	"fmt"
 	"game_hub/core"
 )
 
@@ -15,3 +16,26 @@ func (g *Game) GetId() string {
 func (g *Game) GetStartState() core.State {
 	return &StartState{}
 }
+
+// SaveData возвращает для автосохранения настройки игры и партию, если она ещё идёт.
+func (g *Game) SaveData() any {
+	snapshot := &Snapshot{Settings: &g.Settings}
+	if g.InProgress() {
+		snapshot.Round = &Round{SecretNumber: g.secretNumber, Attempts: g.attempts}
+	}
+	return snapshot
+}
+
+// RestoreData читает настройки прямо в игру, а сохранённую партию откладывает до Resume.
+func (g *Game) RestoreData() any {
+	g.restored = Snapshot{Settings: &g.Settings}
+	return &g.restored
+}
+
+// Status показывает диапазон и оставшиеся попытки текущей партии.
+func (g *Game) Status(ui *core.UiContext) string {
+	if !g.started {
+		return ""
+	}
+	return fmt.Sprintf(ui.GetLocalizedMsg(ui.GameLocalizer, "status"), g.MinNumber, g.MaxNumber, g.attempts)
+}
@@ -3,6 +3,7 @@ package guessnumber
 import (
 	"fmt"
 	"game_hub/core"
+	"time"
 )
 
 type BaseGameState struct {
@@ -25,8 +26,15 @@ func (b *BaseGameState) Init(ctx *core.AppContext, ui *core.UiContext) (core.Sta
 
 type StartState struct{ BaseGameState }
 
-func (s *StartState) Handle(ctx *core.AppContext, ui *core.UiContext, _ string) (core.State, error) {
-	return NewMainMenu(ctx, ui, s.game), nil
+// Handle продолжает партию, прерванную выходом из игры или из программы; иначе открывается главное меню.
+// Начальное состояние остаётся под продолженной партией, поэтому после неё тоже можно вернуться в меню.
+func (s *StartState) Handle(ctx *core.AppContext, ui *core.UiContext, _ string) (core.Transition, error) {
+	if s.game.Resume() {
+		ui.DisplayText(fmt.Sprintf(ui.GetLocalizedMsg(ui.GameLocalizer, "round_resumed")+"\r\n", s.game.MinNumber, s.game.MaxNumber))
+		return core.Push(&GameState{}), nil
+	}
+	s.game.Abandon()
+	return core.Replace(NewMainMenu(ctx, ui, s.game)), nil
 }
 
 func (s *StartState) RequiresInput() bool {
@@ -43,18 +51,23 @@ func NewMainMenu(ctx *core.AppContext, ui *core.UiContext, game *Game) *core.Men
 	options := []core.MenuOption{
 		{Id: 0,
 			Description: "exit_option",
-			NextState:   func() core.State { return &core.GameExitState{} },
+			Next:        func() core.Transition { return core.Push(&core.GameExitState{}) },
 		},
 		{Id: 1,
 			Description: "start_game",
 			Params: func() map[string]any {
 				return map[string]any{"difficulty": ui.GetLocalizedMsg(ui.GameLocalizer, game.Difficulty.String())}
 			},
-			NextState: func() core.State { return &SelectMinNumberState{} },
+			Next: func() core.Transition { return core.Push(&SelectMinNumberState{}) },
 		},
 		{Id: 2,
 			Description: "select_difficulty",
-			NextState:   func() core.State { return &SelectDifficultyMenuState{} },
+			Next:        func() core.Transition { return core.Push(&SelectDifficultyMenuState{}) },
+		},
+		{Id: 3,
+			Description: "select_time_limit",
+			Params:      func() map[string]any { return map[string]any{"seconds": game.GuessTimeLimit} },
+			Next:        func() core.Transition { return core.Push(&SelectTimeLimitState{}) },
 		},
 	}
 	return core.NewMenu(parentState, options, "")
@@ -73,13 +86,13 @@ func (s *SelectMinNumberState) Display(ctx *core.AppContext, ui *core.UiContext)
 	ui.DisplayText(fmt.Sprintf(ui.GetLocalizedMsg(ui.GameLocalizer, "current_value")+"\r\n", s.game.MinNumber))
 }
 
-func (s *SelectMinNumberState) Handle(ctx *core.AppContext, ui *core.UiContext, input string) (core.State, error) {
+func (s *SelectMinNumberState) Handle(ctx *core.AppContext, ui *core.UiContext, input string) (core.Transition, error) {
 	num, err := ui.Validator.ParseOptionalIntInRange(input, s.game.MinNumber, s.game.MinRangeNumber, s.game.MaxRangeNumber)
 	if err != nil {
-		return s, err
+		return core.Stay(), err
 	}
 	s.game.MinNumber = num
-	return &SelectMaxNumberState{}, nil
+	return core.Push(&SelectMaxNumberState{}), nil
 }
 
 func (s *SelectMinNumberState) GetCommands() []core.Command {
@@ -101,17 +114,18 @@ func (s *SelectMaxNumberState) Display(ctx *core.AppContext, ui *core.UiContext)
 	ui.DisplayText(fmt.Sprintf(ui.GetLocalizedMsg(ui.GameLocalizer, "current_value")+"\r\n", s.game.MaxNumber))
 }
 
-func (s *SelectMaxNumberState) Handle(ctx *core.AppContext, ui *core.UiContext, input string) (core.State, error) {
+func (s *SelectMaxNumberState) Handle(ctx *core.AppContext, ui *core.UiContext, input string) (core.Transition, error) {
 	num, err := ui.Validator.ParseOptionalIntInRange(input, s.game.MaxNumber, s.game.MinNumber, s.game.MaxRangeNumber)
 	if err != nil {
-		return s, err
+		return core.Stay(), err
 	}
 	if (num - s.game.MinNumber) < s.game.MinRangeSize {
 		ui.DisplayText(fmt.Sprintf(ui.GetLocalizedStateMsg(s, "range_too_small")+"\r\n", s.game.MinRangeSize))
-		return s, nil
+		return core.Stay(), nil
 	}
 	s.game.MaxNumber = num
-	return &StartGameState{}, nil
+	// после начала игры к выбору диапазона не возвращаются, поэтому история не растёт
+	return core.Replace(&StartGameState{}), nil
 }
 
 func (s *SelectMaxNumberState) GetCommands() []core.Command {
@@ -138,12 +152,17 @@ func (g *StartGameState) Init(ctx *core.AppContext, ui *core.UiContext) (core.St
 	return g, nil
 }
 
+func (g *StartGameState) OnEnter(ctx *core.AppContext, ui *core.UiContext) error {
+	ctx.Events.Publish(core.GameStarted{GameId: g.game.GetId()})
+	return nil
+}
+
 func (g *StartGameState) Display(ctx *core.AppContext, ui *core.UiContext) {
 	ui.DisplayText(fmt.Sprintf(ui.GetLocalizedStateMsg(g, "game_start")+"\r\n", g.game.MinNumber, g.game.MaxNumber, g.game.GetAttempts()))
 }
 
-func (g *StartGameState) Handle(_ *core.AppContext, _ *core.UiContext, _ string) (core.State, error) {
-	return &GameState{}, nil
+func (g *StartGameState) Handle(_ *core.AppContext, _ *core.UiContext, _ string) (core.Transition, error) {
+	return core.Replace(&GameState{}), nil
 }
 
 func (g *StartGameState) RequiresInput() bool {
@@ -159,31 +178,54 @@ func (g *GameState) Id() string {
 
 func (g *GameState) Display(ctx *core.AppContext, ui *core.UiContext) {
 	ui.DisplayText(fmt.Sprintf(ui.GetLocalizedStateMsg(g, "attempts_left")+"\r\n", g.game.GetAttempts()))
+	if g.game.GuessTimeLimit > 0 {
+		ui.DisplayText(fmt.Sprintf(ui.GetLocalizedStateMsg(g, "time_limit")+"\r\n", g.game.GuessTimeLimit))
+	}
+}
+
+// InputTimeout включает блиц: на каждую попытку даётся ограниченное время.
+func (g *GameState) InputTimeout(ctx *core.AppContext, ui *core.UiContext) time.Duration {
+	return time.Duration(g.game.GuessTimeLimit) * time.Second
 }
 
-func (g *GameState) Handle(ctx *core.AppContext, ui *core.UiContext, input string) (core.State, error) {
+// HandleTimeout списывает попытку, если игрок не успел назвать число.
+func (g *GameState) HandleTimeout(ctx *core.AppContext, ui *core.UiContext) (core.Transition, error) {
+	g.game.MissGuess()
+	ui.DisplayText(ui.GetLocalizedStateMsg(g, "time_up") + "\r\n")
+	if g.game.CheckLoss() {
+		return core.Replace(&EndGameState{}), nil
+	}
+	return core.Stay(), nil
+}
+
+func (g *GameState) Handle(ctx *core.AppContext, ui *core.UiContext, input string) (core.Transition, error) {
 	num, err := ui.Validator.ParseIntInRange(input, g.game.MinNumber, g.game.MaxNumber)
 	if err != nil {
-		return g, err
+		return core.Stay(), err
 	}
 	return g.Guess(ctx, ui, num)
 }
 
-func (g *GameState) Guess(ctx *core.AppContext, ui *core.UiContext, guess int) (core.State, error) {
+func (g *GameState) Guess(ctx *core.AppContext, ui *core.UiContext, guess int) (core.Transition, error) {
 	g.game.MakeGuess(guess)
+	ctx.Events.Publish(core.GuessMade{
+		GameId:       g.game.GetId(),
+		Guess:        guess,
+		Correct:      g.game.CheckWin(),
+		AttemptsLeft: g.game.GetAttempts(),
+	})
 	if g.game.CheckWin() {
-		return &EndGameState{}, nil
+		return core.Replace(&EndGameState{}), nil
 	}
 	if g.game.CheckLoss() {
-		return &EndGameState{}, nil
+		return core.Replace(&EndGameState{}), nil
 	}
 	ui.DisplayText(fmt.Sprintf("%s\r\n", ui.GetLocalizedStateMsg(g, g.game.GetHint(guess))))
-	return g, nil
+	return core.Stay(), nil
 }
 
 func (g *GameState) GetCommands() []core.Command {
 	return []core.Command{
-		&core.ExitCommand{},
 		&RestartCommand{},
 	}
 }
@@ -203,8 +245,17 @@ func (e *EndGameState) Display(ctx *core.AppContext, ui *core.UiContext) {
 	}
 }
 
-func (e *EndGameState) Handle(ctx *core.AppContext, ui *core.UiContext, _ string) (core.State, error) {
-	return NewEndMenu(ctx, ui, e.game), nil
+func (e *EndGameState) Handle(ctx *core.AppContext, ui *core.UiContext, _ string) (core.Transition, error) {
+	return core.Replace(NewEndMenu(ctx, ui, e.game)), nil
+}
+
+func (e *EndGameState) OnEnter(ctx *core.AppContext, ui *core.UiContext) error {
+	outcome := core.OutcomeLoss
+	if e.game.CheckWin() {
+		outcome = core.OutcomeWin
+	}
+	ctx.Events.Publish(core.GameFinished{GameId: e.game.GetId(), Outcome: outcome})
+	return nil
 }
 
 func (e *EndGameState) RequiresInput() bool {
@@ -223,15 +274,15 @@ func NewEndMenu(ctx *core.AppContext, ui *core.UiContext, game *Game) *core.Menu
 	options := []core.MenuOption{
 		{Id: 1,
 			Description: "retry",
-			NextState:   func() core.State { return &StartGameState{} },
+			Next:        func() core.Transition { return core.Replace(&StartGameState{}) },
 		},
 		{Id: 2,
 			Description: "change_difficulty",
-			NextState:   func() core.State { return &SelectDifficultyMenuState{} },
+			Next:        func() core.Transition { return core.Push(&SelectDifficultyMenuState{}) },
 		},
 		{Id: 3,
 			Description: "main_menu",
-			NextState:   func() core.State { return NewMainMenu(ctx, ui, game) },
+			Next:        func() core.Transition { return core.ResetToGameStart() },
 		},
 	}
 	return core.NewMenu(parentState, options, "")
@@ -247,24 +298,24 @@ func (s *SelectDifficultyMenuState) Id() string {
 func (s *SelectDifficultyMenuState) Display(ctx *core.AppContext, ui *core.UiContext) {
 	ui.DisplayText(ui.GetLocalizedStateMsg(s, "prompt") + "\r\n")
 	for d := VeryEasy; d <= VeryHard; d++ {
-		ui.DisplayText(fmt.Sprintf("%d. %s.\r\n", d, ui.GetLocalizedMsg(ui.GameLocalizer, d.String())))
+		ui.DisplayText(fmt.Sprintf("%s %s.\r\n", core.Style(core.StyleOption, fmt.Sprintf("%d.", d)), ui.GetLocalizedMsg(ui.GameLocalizer, d.String())))
 	}
 }
 
-func (s *SelectDifficultyMenuState) Handle(ctx *core.AppContext, ui *core.UiContext, input string) (core.State, error) {
+func (s *SelectDifficultyMenuState) Handle(ctx *core.AppContext, ui *core.UiContext, input string) (core.Transition, error) {
 	num, err := ui.Validator.ParseInt(input)
 	if err != nil {
 		ui.DisplayText(ui.GetLocalizedStateMsg(s, "invalid_input") + "\r\n")
-		return s, nil
+		return core.Stay(), nil
 	}
 	diff := Difficulty(num)
 	if diff < VeryEasy || diff > VeryHard {
 		ui.DisplayText(ui.GetLocalizedStateMsg(s, "invalid_option") + "\r\n")
-		return s, nil
+		return core.Stay(), nil
 	}
 	s.game.Difficulty = diff
 	ui.DisplayText(fmt.Sprintf(ui.GetLocalizedStateMsg(s, "selected")+"\r\n", ui.GetLocalizedMsg(ui.GameLocalizer, diff.String())))
-	return ctx.GetPreviousState()
+	return core.Pop(), nil
 }
 
 func (s *SelectDifficultyMenuState) GetCommands() []core.Command {
@@ -272,3 +323,35 @@ func (s *SelectDifficultyMenuState) GetCommands() []core.Command {
 		&core.BackCommand{},
 	}
 }
+
+// выбор времени на попытку
+type SelectTimeLimitState struct{ BaseGameState }
+
+func (s *SelectTimeLimitState) Id() string {
+	return "select_time_limit"
+}
+
+func (s *SelectTimeLimitState) Display(ctx *core.AppContext, ui *core.UiContext) {
+	ui.DisplayText(fmt.Sprintf(ui.GetLocalizedStateMsg(s, "prompt")+"\r\n", s.game.MaxTimeLimit))
+	ui.DisplayText(fmt.Sprintf(ui.GetLocalizedStateMsg(s, "current_value")+"\r\n", s.game.GuessTimeLimit))
+}
+
+func (s *SelectTimeLimitState) Handle(ctx *core.AppContext, ui *core.UiContext, input string) (core.Transition, error) {
+	seconds, err := ui.Validator.ParseOptionalIntInRange(input, s.game.GuessTimeLimit, 0, s.game.MaxTimeLimit)
+	if err != nil {
+		return core.Stay(), err
+	}
+	s.game.GuessTimeLimit = seconds
+	if seconds == 0 {
+		ui.DisplayText(ui.GetLocalizedStateMsg(s, "disabled") + "\r\n")
+	} else {
+		ui.DisplayText(fmt.Sprintf(ui.GetLocalizedStateMsg(s, "selected")+"\r\n", seconds))
+	}
+	return core.Pop(), nil
+}
+
+func (s *SelectTimeLimitState) GetCommands() []core.Command {
+	return []core.Command{
+		&core.BackCommand{},
+	}
+}
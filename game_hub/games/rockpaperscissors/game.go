@@ -33,28 +33,63 @@ const (
 	Loss
 )
 
+func (r RoundResult) Outcome() core.GameOutcome {
+	switch r {
+	case Winning:
+		return core.OutcomeWin
+	case Loss:
+		return core.OutcomeLoss
+	default:
+		return core.OutcomeDraw
+	}
+}
+
+// Settings — выбранные игроком параметры, которые сохраняются между запусками
+type Settings struct {
+	TotalRounds int `json:"total_rounds" validate:"min=1"`
+	// время на ход в секундах, 0 — без ограничения
+	MoveTimeLimit int `json:"move_time_limit" validate:"min=0"`
+}
+
+// Round — незаконченная партия: она попадает в сохранение, чтобы её можно было доиграть после перезапуска
+type Round struct {
+	Number      int `json:"number" validate:"min=1"`
+	PlayerScore int `json:"player_score" validate:"min=0"`
+	BotScore    int `json:"bot_score" validate:"min=0"`
+}
+
+// Snapshot — сохранение игры: настройки и партия, если игрок вышел посреди неё
+type Snapshot struct {
+	*Settings
+	Round *Round `json:"round,omitempty"`
+}
+
 type Game struct {
+	Settings
 	MinRounds       int
 	MaxRounds       int
+	MaxTimeLimit    int
 	PlayerScore     int
 	BotScore        int
 	CurrentRound    int
-	TotalRounds     int
 	PlayerMove      Move
 	BotMove         Move
 	winTable        [3][3]RoundResult
 	isWon           bool
 	isLoss          bool
 	RandomGenerator *core.RandomGenerator
+	// restored — сохранение, прочитанное при загрузке игры; партию из него берёт только Resume
+	restored Snapshot
 }
 
 func NewGame() *Game {
 	return &Game{
-		MinRounds:   3,
-		MaxRounds:   100,
-		PlayerScore: 0,
-		BotScore:    0,
-		TotalRounds: 3,
+		MinRounds:    3,
+		MaxRounds:    100,
+		MaxTimeLimit: 60,
+		PlayerScore:  0,
+		BotScore:     0,
+		Settings:     Settings{TotalRounds: 3},
 		winTable: [3][3]RoundResult{
 			{Draw, Winning, Loss},
 			{Loss, Draw, Winning},
@@ -67,11 +102,36 @@ func NewGame() *Game {
 }
 
 func (g *Game) Reset() {
+	// новая партия отменяет сохранённую: к ней уже не вернуться, что бы ни сохранялось дальше
+	g.restored.Round = nil
 	g.CurrentRound = 1
 	g.PlayerScore, g.BotScore = 0, 0
 	g.isWon, g.isLoss = false, false
 }
 
+// InProgress сообщает, идёт ли партия: первый раунд начат, а последний ещё не сыгран.
+func (g *Game) InProgress() bool {
+	return g.CurrentRound > 0 && !g.IsOver()
+}
+
+// Resume продолжает партию из восстановленного сохранения. Сохранённая партия продолжается только один раз,
+// а партия, в которой раундов больше, чем в настройках, считается испорченной и отбрасывается.
+func (g *Game) Resume() bool {
+	round := g.restored.Round
+	g.restored.Round = nil
+	if round == nil || round.Number > g.TotalRounds {
+		return false
+	}
+	g.Reset()
+	g.CurrentRound, g.PlayerScore, g.BotScore = round.Number, round.PlayerScore, round.BotScore
+	return true
+}
+
+// Abandon бросает текущую партию, чтобы она не попала в сохранение.
+func (g *Game) Abandon() {
+	g.CurrentRound = 0
+}
+
 func (g *Game) MakePlayerMove(playerMove Move) {
 	g.PlayerMove = playerMove
 }
@@ -101,17 +161,33 @@ func (g *Game) PlayRound() RoundResult {
 		g.PlayerScore++
 		g.BotScore++
 	}
-	if g.CurrentRound > g.TotalRounds {
-		if g.PlayerScore > g.BotScore {
-			g.isWon = true
-		} else if g.PlayerScore < g.BotScore {
-			g.isLoss = true
-		}
-		return result
-	}
+	g.checkGameOver()
 	return result
 }
 
+// ForfeitRound отдаёт раунд сопернику, если игрок не успел сделать ход.
+func (g *Game) ForfeitRound() {
+	g.MakeBotMove()
+	g.CurrentRound++
+	g.BotScore++
+	g.checkGameOver()
+}
+
+func (g *Game) IsOver() bool {
+	return g.CurrentRound > g.TotalRounds
+}
+
+func (g *Game) checkGameOver() {
+	if !g.IsOver() {
+		return
+	}
+	if g.PlayerScore > g.BotScore {
+		g.isWon = true
+	} else if g.PlayerScore < g.BotScore {
+		g.isLoss = true
+	}
+}
+
 func (g *Game) CheckWin() bool {
 	return g.isWon
 }
@@ -1,6 +1,7 @@
 package rockpaperscissors
 
 import (
+	"fmt"
 	"game_hub/core"
 )
 
@@ -15,3 +16,27 @@ func (g *Game) GetId() string {
 func (g *Game) GetStartState() core.State {
 	return &StartState{}
 }
+
+// SaveData возвращает для автосохранения настройки игры и партию, если она ещё идёт.
+func (g *Game) SaveData() any {
+	snapshot := &Snapshot{Settings: &g.Settings}
+	if g.InProgress() {
+		snapshot.Round = &Round{Number: g.CurrentRound, PlayerScore: g.PlayerScore, BotScore: g.BotScore}
+	}
+	return snapshot
+}
+
+// RestoreData читает настройки прямо в игру, а сохранённую партию откладывает до Resume.
+func (g *Game) RestoreData() any {
+	g.restored = Snapshot{Settings: &g.Settings}
+	return &g.restored
+}
+
+// Status показывает текущий раунд и счёт.
+func (g *Game) Status(ui *core.UiContext) string {
+	if g.CurrentRound == 0 {
+		return ""
+	}
+	round := min(g.CurrentRound, g.TotalRounds)
+	return fmt.Sprintf(ui.GetLocalizedMsg(ui.GameLocalizer, "status"), round, g.TotalRounds, g.PlayerScore, g.BotScore)
+}
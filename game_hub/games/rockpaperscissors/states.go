@@ -3,8 +3,15 @@ package rockpaperscissors
 import (
 	"fmt"
 	"game_hub/core"
+	"time"
 )
 
+// moveTimer — id таймера, ограничивающего время на ход
+const moveTimer = "move"
+
+// moveOptions сопоставляет номера пунктов в подсказке ходам
+var moveOptions = map[int]Move{1: Rock, 2: Scissors, 3: Paper}
+
 type BaseGameState struct {
 	core.BaseState
 	game *Game
@@ -25,8 +32,15 @@ func (b *BaseGameState) Init(ctx *core.AppContext, ui *core.UiContext) (core.Sta
 
 type StartState struct{ BaseGameState }
 
-func (s *StartState) Handle(ctx *core.AppContext, ui *core.UiContext, _ string) (core.State, error) {
-	return NewMainMenu(ctx, ui, s.game), nil
+// Handle продолжает партию, прерванную выходом из игры или из программы; иначе открывается главное меню.
+// Начальное состояние остаётся под продолженной партией: после её конца оно снова открывает меню.
+func (s *StartState) Handle(ctx *core.AppContext, ui *core.UiContext, _ string) (core.Transition, error) {
+	if s.game.Resume() {
+		ui.DisplayText(ui.GetLocalizedMsg(ui.GameLocalizer, "round_resumed") + "\r\n")
+		return core.Push(&GameState{resumed: true}), nil
+	}
+	s.game.Abandon()
+	return core.Replace(NewMainMenu(ctx, ui, s.game)), nil
 }
 
 func (s *StartState) RequiresInput() bool {
@@ -44,25 +58,34 @@ func NewMainMenu(ctx *core.AppContext, ui *core.UiContext, game *Game) *core.Men
 	options := []core.MenuOption{
 		{Id: 0,
 			Description: "exit_option",
-			NextState:   func() core.State { return &core.GameExitState{} },
+			Next:        func() core.Transition { return core.Push(&core.GameExitState{}) },
 		},
 		{Id: 1,
 			Description: "start_game",
 			Params:      func() map[string]any { return map[string]any{"rounds": game.TotalRounds} },
-			NextState: func() core.State {
+			Next: func() core.Transition {
 				game.Reset()
-				return &GameState{}
+				return core.Push(&GameState{})
 			},
 		},
 		{Id: 2,
 			Description: "select_rounds",
-			NextState:   func() core.State { return &SelectRoundsState{} },
+			Next:        func() core.Transition { return core.Push(&SelectRoundsState{}) },
+		},
+		{Id: 3,
+			Description: "select_time_limit",
+			Params:      func() map[string]any { return map[string]any{"seconds": game.MoveTimeLimit} },
+			Next:        func() core.Transition { return core.Push(&SelectTimeLimitState{}) },
 		},
 	}
 	return core.NewMenu(parentState, options, "")
 }
 
-type GameState struct{ BaseGameState }
+type GameState struct {
+	BaseGameState
+	// resumed — партия продолжена из сохранения, а не начата заново
+	resumed bool
+}
 
 func (g *GameState) Id() string {
 	return "game"
@@ -71,34 +94,53 @@ func (g *GameState) Id() string {
 func (g *GameState) Display(ctx *core.AppContext, ui *core.UiContext) {
 	ui.DisplayText(fmt.Sprintf(ui.GetLocalizedStateMsg(g, "score")+"\r\n", g.game.PlayerScore, g.game.BotScore))
 	ui.DisplayText(fmt.Sprintf(ui.GetLocalizedStateMsg(g, "current_round")+"\r\n", g.game.CurrentRound, g.game.TotalRounds))
+	if g.game.MoveTimeLimit > 0 {
+		ui.DisplayText(fmt.Sprintf(ui.GetLocalizedStateMsg(g, "time_limit")+"\r\n", g.game.MoveTimeLimit))
+	}
 	ui.DisplayText(ui.GetLocalizedStateMsg(g, "prompt") + "\r\n")
 }
 
-func (g *GameState) Handle(ctx *core.AppContext, ui *core.UiContext, input string) (core.State, error) {
+func (g *GameState) Handle(ctx *core.AppContext, ui *core.UiContext, input string) (core.Transition, error) {
 	option, err := ui.Validator.ParseInt(input)
 	if err != nil {
-		return g, err
-	}
-	var playerMove Move
-	switch option {
-	case 1:
-		playerMove = Rock
-	case 2:
-		playerMove = Scissors
-	case 3:
-		playerMove = Paper
-	default:
+		return core.Stay(), err
+	}
+	playerMove, exists := moveOptions[option]
+	if !exists {
 		ui.DisplayText(ui.GetLocalizedStateMsg(g, "invalid_option") + "\r\n")
-		return g, nil
+		return core.Stay(), nil
 	}
 	return g.Play(ctx, ui, playerMove)
 }
 
-func (g *GameState) Play(ctx *core.AppContext, ui *core.UiContext, playerMove Move) (core.State, error) {
+func (g *GameState) KeyPrompt(ctx *core.AppContext, ui *core.UiContext) string {
+	return "> "
+}
+
+// HandleKey делает ход сразу по нажатию цифры; остальные клавиши начинают ввод команды.
+func (g *GameState) HandleKey(ctx *core.AppContext, ui *core.UiContext, key core.Key) (core.Transition, core.KeyResult, error) {
+	digit, ok := key.Digit()
+	if !ok {
+		return core.Stay(), core.KeyIgnored, nil
+	}
+	move, exists := moveOptions[digit]
+	if !exists {
+		return core.Stay(), core.KeyConsumed, nil
+	}
+	transition, err := g.Play(ctx, ui, move)
+	return transition, core.KeyHandled, err
+}
+
+func (g *GameState) Play(ctx *core.AppContext, ui *core.UiContext, playerMove Move) (core.Transition, error) {
 	g.game.MakePlayerMove(playerMove)
 	g.game.MakeBotMove()
 	ui.DisplayText(fmt.Sprintf(ui.GetLocalizedStateMsg(g, "moves_info")+"\r\n", ui.GetLocalizedMsg(ui.GameLocalizer, g.game.PlayerMove.String()), ui.GetLocalizedMsg(ui.GameLocalizer, g.game.BotMove.String())))
 	result := g.game.PlayRound()
+	ctx.Events.Publish(core.RoundPlayed{
+		GameId:  g.game.GetId(),
+		Round:   g.game.CurrentRound - 1,
+		Outcome: result.Outcome(),
+	})
 	switch result {
 	case Winning:
 		ui.DisplayText(ui.GetLocalizedStateMsg(g, "round_win") + "\r\n")
@@ -107,10 +149,62 @@ func (g *GameState) Play(ctx *core.AppContext, ui *core.UiContext, playerMove Mo
 	case Draw:
 		ui.DisplayText(ui.GetLocalizedStateMsg(g, "round_draw") + "\r\n")
 	}
-	if g.game.CurrentRound > g.game.TotalRounds {
-		return &EndGameState{}, nil
+	return g.nextRound(ctx)
+}
+
+// HandleEvent отдаёт раунд сопернику, если игрок не успел сделать ход.
+func (g *GameState) HandleEvent(ctx *core.AppContext, ui *core.UiContext, event core.Event) (core.Transition, error) {
+	if timer, ok := event.(core.TimerFired); !ok || timer.Id != moveTimer {
+		return core.Stay(), nil
 	}
-	return g, nil
+	g.game.ForfeitRound()
+	ui.DisplayText(ui.GetLocalizedStateMsg(g, "time_up") + "\r\n")
+	ctx.Events.Publish(core.RoundPlayed{
+		GameId:  g.game.GetId(),
+		Round:   g.game.CurrentRound - 1,
+		Outcome: core.OutcomeLoss,
+	})
+	return g.nextRound(ctx)
+}
+
+func (g *GameState) nextRound(ctx *core.AppContext) (core.Transition, error) {
+	if g.game.IsOver() {
+		return core.Replace(&EndGameState{}), nil
+	}
+	g.startMoveTimer(ctx)
+	return core.Stay(), nil
+}
+
+// startMoveTimer заново отсчитывает время на ход, если оно ограничено.
+func (g *GameState) startMoveTimer(ctx *core.AppContext) {
+	if g.game.MoveTimeLimit > 0 {
+		ctx.Scheduler.After(moveTimer, time.Duration(g.game.MoveTimeLimit)*time.Second)
+	}
+}
+
+// OnEnter отмечает начало партии, если она запущена из меню, а не продолжена из сохранения.
+func (g *GameState) OnEnter(ctx *core.AppContext, ui *core.UiContext) error {
+	if !g.resumed {
+		ctx.Events.Publish(core.GameStarted{GameId: g.game.GetId()})
+	}
+	g.startMoveTimer(ctx)
+	return nil
+}
+
+// OnPause останавливает отсчёт, пока поверх игры открыт диалог, а OnResume даёт на ход полное время.
+func (g *GameState) OnPause(ctx *core.AppContext, ui *core.UiContext) error {
+	ctx.Scheduler.Cancel(moveTimer)
+	return nil
+}
+
+func (g *GameState) OnResume(ctx *core.AppContext, ui *core.UiContext) error {
+	g.startMoveTimer(ctx)
+	return nil
+}
+
+func (g *GameState) OnExit(ctx *core.AppContext, ui *core.UiContext) error {
+	ctx.Scheduler.Cancel(moveTimer)
+	return nil
 }
 
 type EndGameState struct{ BaseGameState }
@@ -130,8 +224,19 @@ func (e *EndGameState) Display(ctx *core.AppContext, ui *core.UiContext) {
 	}
 }
 
-func (e *EndGameState) Handle(ctx *core.AppContext, ui *core.UiContext, _ string) (core.State, error) {
-	return NewMainMenu(ctx, ui, e.game), nil
+func (e *EndGameState) Handle(ctx *core.AppContext, ui *core.UiContext, _ string) (core.Transition, error) {
+	return core.Pop(), nil
+}
+
+func (e *EndGameState) OnEnter(ctx *core.AppContext, ui *core.UiContext) error {
+	outcome := core.OutcomeDraw
+	if e.game.CheckWin() {
+		outcome = core.OutcomeWin
+	} else if e.game.CheckLoss() {
+		outcome = core.OutcomeLoss
+	}
+	ctx.Events.Publish(core.GameFinished{GameId: e.game.GetId(), Outcome: outcome})
+	return nil
 }
 
 func (e *EndGameState) RequiresInput() bool {
@@ -149,14 +254,14 @@ func (s *SelectRoundsState) Display(ctx *core.AppContext, ui *core.UiContext) {
 	ui.DisplayText(fmt.Sprintf(ui.GetLocalizedStateMsg(s, "current_value")+"\r\n", s.game.TotalRounds))
 }
 
-func (s *SelectRoundsState) Handle(ctx *core.AppContext, ui *core.UiContext, input string) (core.State, error) {
+func (s *SelectRoundsState) Handle(ctx *core.AppContext, ui *core.UiContext, input string) (core.Transition, error) {
 	num, err := ui.Validator.ParseOptionalIntInRange(input, s.game.TotalRounds, s.game.MinRounds, s.game.MaxRounds)
 	if err != nil {
-		return s, err
+		return core.Stay(), err
 	}
 	s.game.TotalRounds = num
 	ui.DisplayText(fmt.Sprintf(ui.GetLocalizedStateMsg(s, "selected")+"\r\n", num))
-	return ctx.GetPreviousState()
+	return core.Pop(), nil
 }
 
 func (s *SelectRoundsState) GetCommands() []core.Command {
@@ -164,3 +269,34 @@ func (s *SelectRoundsState) GetCommands() []core.Command {
 		&core.BackCommand{},
 	}
 }
+
+type SelectTimeLimitState struct{ BaseGameState }
+
+func (s *SelectTimeLimitState) Id() string {
+	return "select_time_limit"
+}
+
+func (s *SelectTimeLimitState) Display(ctx *core.AppContext, ui *core.UiContext) {
+	ui.DisplayText(fmt.Sprintf(ui.GetLocalizedStateMsg(s, "prompt")+"\r\n", s.game.MaxTimeLimit))
+	ui.DisplayText(fmt.Sprintf(ui.GetLocalizedStateMsg(s, "current_value")+"\r\n", s.game.MoveTimeLimit))
+}
+
+func (s *SelectTimeLimitState) Handle(ctx *core.AppContext, ui *core.UiContext, input string) (core.Transition, error) {
+	seconds, err := ui.Validator.ParseOptionalIntInRange(input, s.game.MoveTimeLimit, 0, s.game.MaxTimeLimit)
+	if err != nil {
+		return core.Stay(), err
+	}
+	s.game.MoveTimeLimit = seconds
+	if seconds == 0 {
+		ui.DisplayText(ui.GetLocalizedStateMsg(s, "disabled") + "\r\n")
+	} else {
+		ui.DisplayText(fmt.Sprintf(ui.GetLocalizedStateMsg(s, "selected")+"\r\n", seconds))
+	}
+	return core.Pop(), nil
+}
+
+func (s *SelectTimeLimitState) GetCommands() []core.Command {
+	return []core.Command{
+		&core.BackCommand{},
+	}
+}
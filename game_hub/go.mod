@@ -5,6 +5,9 @@ go 1.24.0
 require (
 	github.com/chzyer/readline v1.5.1
 	github.com/go-playground/validator/v10 v10.26.0
+	github.com/pelletier/go-toml/v2 v2.4.3
+	golang.org/x/text v0.22.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -16,5 +19,4 @@ require (
 	golang.org/x/crypto v0.33.0 // indirect
 	golang.org/x/net v0.34.0 // indirect
 	golang.org/x/sys v0.30.0 // indirect
-	golang.org/x/text v0.22.0 // indirect
 )
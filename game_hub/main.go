@@ -1,12 +1,21 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"game_hub/app"
+	"game_hub/cli"
 	"game_hub/config"
 	"game_hub/core"
 	"game_hub/games"
+	"game_hub/tui"
+	"io"
+	"log/slog"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
 )
 
 func main() {
@@ -16,15 +25,30 @@ func main() {
 		fmt.Printf("Failed to initialize Configuration: %v\r\n", err)
 		return
 	}
+	if cli.IsCommand(os.Args[1:]) {
+		if err := cli.Run(cfg, availableGames, os.Args[1:]); err != nil {
+			os.Exit(1)
+		}
+		return
+	}
+	if err := parseFlags(cfg, os.Args[1:]); err != nil {
+		os.Exit(2)
+	}
+	// настройки читаются до создания консоли: в режиме доступности полноэкранный интерфейс не используется
+	settings, settingsErr := core.LoadSettings(cfg)
+	if settings != nil && settings.Accessible {
+		cfg.Accessible = true
+	}
 	appCtx := &core.AppContext{
 		Config:         cfg,
 		StateStack:     core.NewStateStack(),
 		Game:           nil,
 		AvailableGames: availableGames,
-		AppIsRunning:   true,
-		GoToMenu:       false,
+		Events:         core.NewEventBus(),
+		Scheduler:      core.NewScheduler(),
 	}
-	console, err := core.NewReadlineConsole()
+	historySize := historySize(cfg, settings)
+	console, screen, err := newConsole(cfg, historySize)
 	if err != nil {
 		fmt.Printf("Failed to initialize console: %v\r\n", err)
 		return
@@ -41,7 +65,16 @@ func main() {
 	}
 	appMessageLocalizer := core.NewMessageLocalizer(lm)
 	errorHandler := core.NewLocalizedErrorHandler(appMessageLocalizer)
-	logger := core.NewStdLogger(os.Stdout, errorHandler)
+	logOutput := io.Writer(os.Stdout)
+	if screen != nil {
+		logOutput = screen.LogOutput()
+	}
+	logger, closeLog := newLogger(cfg, settings, errorHandler, logOutput)
+	defer func() {
+		if logErr := closeLog(); logErr != nil && err == nil {
+			err = logErr
+		}
+	}()
 	lm.SetLogger(logger)
 	uiCtx := &core.UiContext{
 		Console:             console,
@@ -53,6 +86,14 @@ func main() {
 		AppLocalizer:        appMessageLocalizer,
 		GameLocalizer:       core.NewMessageLocalizer(lm),
 		StateLocalizer:      core.NewStateLocalizer(lm),
+		WrapWidth:           cfg.WrapWidth,
+	}
+	uiCtx.SetAccessible(cfg.Accessible)
+	if cfg.Debug {
+		uiCtx.CommandRegistry.Use(core.AuditMiddleware(logger))
+		appCtx.Events.SubscribeAll(func(event core.Event) {
+			logger.Debug("event", "name", event.EventName(), "data", fmt.Sprintf("%+v", event))
+		})
 	}
 	if err := uiCtx.AppLocalizer.LoadTranslations(appCtx.Config.Paths.CoreTranslationsPath()); err != nil {
 		uiCtx.DisplayError(err)
@@ -74,52 +115,133 @@ func main() {
 		uiCtx.DisplayError(err)
 		return
 	}
-	startState := core.State(&app.StartState{})
-	runMainLoop(appCtx, uiCtx, startState)
-}
-
-func runMainLoop(appCtx *core.AppContext, uiCtx *core.UiContext, startState core.State) {
-	currentState, err := appCtx.GoToState(startState, uiCtx)
-	uiCtx.DisplayError(err)
-	if currentState == nil {
-		return
+	if theme, err := core.LoadTheme(cfg); err != nil {
+		logger.Error(err)
+	} else {
+		uiCtx.Theme = theme
 	}
-	for appCtx.AppIsRunning {
-		currentState.Display(appCtx, uiCtx)
-		input := ""
-		if currentState.RequiresInput() {
-			buf, inputErr := uiCtx.Console.Read()
-			uiCtx.DisplayError(inputErr)
-			if appErr, ok := inputErr.(*core.AppError); ok && appErr.Code == core.ErrEOF {
-				currentState, err := appCtx.GoToState(&core.ExitState{}, uiCtx)
-				uiCtx.DisplayError(err)
-				currentState.Display(appCtx, uiCtx)
-			}
-			input = buf
+	if settingsErr != nil {
+		logger.Error(settingsErr)
+	} else if settings != nil && settings.Language != "" {
+		previousLang := lm.CurrentLang()
+		if err := lm.SetCurrentLanguage(settings.Language); err != nil {
+			logger.Error(err)
+		} else if lang := lm.CurrentLang(); lang != previousLang {
+			appCtx.Events.Publish(core.LanguageChanged{From: previousLang, To: lang})
 		}
-		nextState, err := uiCtx.HandleInput(input, appCtx)
+		uiCtx.CommandRegistry.UpdateAliases()
+	}
+	engine := core.NewEngine(appCtx, uiCtx, &app.StartState{})
+	engine.History = core.NewInputHistory(cfg, historySize)
+	if screen != nil {
+		screen.Attach(engine)
+	}
+	stopSignals := engine.ListenForSignals()
+	defer stopSignals()
+	logger.Info("session started", "version", core.Version, "ui", cfg.UI, "language", lm.CurrentLang(), "accessible", uiCtx.Accessible())
+	if err := engine.Run(context.Background()); err != nil {
 		uiCtx.DisplayError(err)
-		if appErr, ok := err.(*core.AppError); ok && appErr.Code == core.ErrStateStack {
-			currentState, err := appCtx.GoToState(startState, uiCtx)
-			uiCtx.DisplayError(err)
-			currentState.Display(appCtx, uiCtx)
+	}
+}
+
+// parseFlags разбирает флаги интерактивного режима.
+func parseFlags(cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet(core.AppName, flag.ContinueOnError)
+	flags.BoolVar(&cfg.Debug, "debug", os.Getenv("GAME_HUB_DEBUG") != "", "enable hidden developer commands")
+	flags.BoolVar(&cfg.Accessible, "accessible", false, "screen-reader friendly mode: plain unwrapped text, explicit prompts and line input")
+	flags.Func("ui", "front-end: \""+config.UILine+"\" (default) or \""+config.UITui+"\" (full screen)", func(value string) error {
+		if value != config.UILine && value != config.UITui {
+			return fmt.Errorf("unknown front-end %q", value)
 		}
-		if appCtx.GoToMenu {
-			appCtx.StateStack.Clear()
-			currentState, err := appCtx.GoToState(startState, uiCtx)
-			uiCtx.DisplayError(err)
-			if currentState == nil {
-				return
-			}
-			appCtx.GoToMenu = false
-			continue
+		cfg.UI = value
+		return nil
+	})
+	flags.Func("log-level", "least severe level written to the log file: "+strings.Join(config.LogLevels, ", "), func(value string) error {
+		if !slices.Contains(config.LogLevels, value) {
+			return fmt.Errorf("unknown log level %q", value)
+		}
+		cfg.LogLevel = value
+		return nil
+	})
+	flags.Func("history-size", "number of lines kept in each input history across runs; 0 disables the history", func(value string) error {
+		size, err := strconv.Atoi(value)
+		if err != nil || size < 0 {
+			return fmt.Errorf("expected a non-negative number of lines, got %q", value)
 		}
-		if nextState != currentState {
-			if nextState == startState {
-				appCtx.StateStack.Clear()
+		cfg.HistorySize = size
+		return nil
+	})
+	flags.Func("wrap", "wrap text at the terminal width (\"auto\", default), at a number of columns, or not at all (\"off\")", func(value string) error {
+		switch value {
+		case "auto":
+			cfg.WrapWidth = config.WrapAuto
+		case "off":
+			cfg.WrapWidth = 0
+		default:
+			columns, err := strconv.Atoi(value)
+			if err != nil || columns < 1 {
+				return fmt.Errorf("expected \"auto\", \"off\" or a positive number of columns, got %q", value)
 			}
-			currentState, err = appCtx.GoToState(nextState, uiCtx)
-			uiCtx.DisplayError(err)
+			cfg.WrapWidth = columns
+		}
+		return nil
+	})
+	return flags.Parse(args)
+}
+
+// newLogger открывает журнал в файле в каталоге состояния пользователя. Если файл недоступен,
+// предупреждения и ошибки журнала выводятся в консоль, как раньше, чтобы не потеряться.
+func newLogger(cfg *config.Config, settings *core.Settings, errorHandler core.ErrorHandler, console io.Writer) (core.Logger, func() error) {
+	level := core.ParseLogLevel(logLevel(cfg, settings))
+	noClose := func() error { return nil }
+	if level == core.LevelOff {
+		return core.NewStdLogger(io.Discard, level, errorHandler), noClose
+	}
+	file, err := core.OpenRotatingFile(cfg.Paths.LogPath())
+	if err != nil {
+		logger := core.NewStdLogger(console, max(level, slog.LevelWarn), errorHandler)
+		logger.Warn("log file is unavailable", "path", cfg.Paths.LogPath(), "error", err)
+		return logger, noClose
+	}
+	return core.NewFileLogger(file, level, errorHandler), file.Close
+}
+
+// logLevel выбирает уровень журнала: флаг важнее настройки, а без них режим отладки пишет подробный журнал.
+func logLevel(cfg *config.Config, settings *core.Settings) string {
+	switch {
+	case cfg.LogLevel != "":
+		return cfg.LogLevel
+	case settings != nil && settings.LogLevel != "":
+		return settings.LogLevel
+	case cfg.Debug:
+		return config.LogDebug
+	}
+	return config.LogInfo
+}
+
+// historySize выбирает размер истории ввода: флаг важнее настройки.
+func historySize(cfg *config.Config, settings *core.Settings) int {
+	switch {
+	case cfg.HistorySize != config.HistoryUnset:
+		return cfg.HistorySize
+	case settings != nil && settings.HistorySize != nil:
+		return *settings.HistorySize
+	}
+	return config.HistoryDefault
+}
+
+// newConsole создаёт консоль выбранного фронтенда. Полноэкранному интерфейсу нужен терминал,
+// без него и в режиме доступности используется обычная построчная консоль.
+func newConsole(cfg *config.Config, historySize int) (core.Console, *tui.Console, error) {
+	if cfg.UI == config.UITui {
+		if cfg.Accessible {
+			fmt.Print("Full-screen interface is unavailable in accessibility mode.\r\n")
+		} else if screen, err := tui.NewConsole(historySize); err == nil {
+			return screen, screen, nil
+		} else {
+			fmt.Printf("Full-screen interface is unavailable: %v\r\n", err)
 		}
 	}
+	console, err := core.NewReadlineConsole(historySize)
+	return console, nil, err
 }
@@ -0,0 +1,276 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"game_hub/core"
+	"github.com/chzyer/readline"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+const (
+	enterAltScreen = "\x1b[?1049h"
+	leaveAltScreen = "\x1b[?1049l"
+)
+
+// Console — полноэкранный интерфейс на альтернативном экране терминала: заголовок с игрой и её состоянием,
+// прокручиваемый журнал сообщений, строка с доступными командами и строка ввода.
+// Состояния выводят в него тот же текст, что и в построчную консоль.
+type Console struct {
+	in        *os.File
+	out       *os.File
+	termState *readline.State
+	// runes получает символы из горутины чтения терминала и закрывается в конце ввода
+	runes  chan rune
+	cancel chan struct{}
+	// pending — символы, прочитанные при разборе escape-последовательности, но относящиеся к следующей клавише
+	pending []rune
+	// history — строки, которые можно вернуть стрелками вверх и вниз, не больше historyLimit
+	history      []string
+	historyLimit int
+	// historyScope — область загруженной истории; nextScope и nextHistory задаёт SetHistory для следующего чтения
+	historyScope string
+	// mu защищает screen и следующую историю: в них пишут и цикл движка, и горутина чтения
+	mu          sync.Mutex
+	screen      screen
+	nextScope   string
+	nextHistory []string
+	closed      bool
+}
+
+// NewConsole переключает терминал на альтернативный экран; без терминала полноэкранный интерфейс недоступен.
+// История ввода хранит до historySize строк, при 0 она не ведётся.
+func NewConsole(historySize int) (*Console, error) {
+	in, out := os.Stdin, os.Stdout
+	if !readline.IsTerminal(int(in.Fd())) || !readline.IsTerminal(int(out.Fd())) {
+		return nil, errors.New("standard input and output must be a terminal")
+	}
+	termState, err := readline.MakeRaw(int(in.Fd()))
+	if err != nil {
+		return nil, err
+	}
+	c := &Console{
+		in:           in,
+		out:          out,
+		termState:    termState,
+		runes:        make(chan rune, 64),
+		cancel:       make(chan struct{}, 1),
+		historyLimit: historySize,
+	}
+	if _, err := c.out.WriteString(enterAltScreen); err != nil {
+		return nil, errors.Join(err, readline.Restore(int(in.Fd()), termState))
+	}
+	go c.readInput()
+	readline.DefaultOnWidthChanged(c.resize)
+	c.resize()
+	return c, nil
+}
+
+// SetHeader меняет заголовок: название хаба или игры слева и её состояние справа.
+func (c *Console) SetHeader(title, status string) {
+	c.update(func(s *screen) {
+		s.title, s.status = title, status
+	})
+}
+
+// SetCommands меняет строку с доступными командами.
+func (c *Console) SetCommands(commands string) {
+	c.update(func(s *screen) {
+		s.commands = commands
+	})
+}
+
+// LogOutput возвращает io.Writer, который добавляет записи лога в журнал, не ломая экран.
+func (c *Console) LogOutput() io.Writer {
+	return logWriter{console: c}
+}
+
+func (c *Console) Read() (string, error) {
+	return c.readLine(context.Background())
+}
+
+func (c *Console) ReadContext(ctx context.Context) (string, error) {
+	return c.readLine(ctx)
+}
+
+func (c *Console) Write(text string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		_, err := c.out.WriteString(text)
+		return err
+	}
+	c.screen.write(text)
+	return c.render()
+}
+
+// TextWidth возвращает 0: журнал переносит строки сам при каждой перерисовке.
+func (c *Console) TextWidth() int {
+	return 0
+}
+
+// SupportsColor всегда разрешает стили: полноэкранный интерфейс работает только в терминале.
+func (c *Console) SupportsColor() bool {
+	return true
+}
+
+// CancelRead прерывает текущее чтение, а если сейчас ничего не читается — следующее.
+func (c *Console) CancelRead() {
+	select {
+	case c.cancel <- struct{}{}:
+	default:
+	}
+}
+
+// RestoreTerminal перерисовывает экран: терминал всё время остаётся в посимвольном режиме,
+// и после сбоя посреди чтения достаточно убрать недописанную строку ввода.
+func (c *Console) RestoreTerminal() error {
+	return c.update(func(s *screen) {
+		s.stopInput()
+	})
+}
+
+// Close возвращает обычный экран и режим терминала.
+func (c *Console) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	_, err := c.out.WriteString(showCursor + leaveAltScreen)
+	return errors.Join(err, readline.Restore(int(c.in.Fd()), c.termState))
+}
+
+func (c *Console) update(change func(s *screen)) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	change(&c.screen)
+	if c.closed {
+		return nil
+	}
+	return c.render()
+}
+
+// render перерисовывает весь экран одной записью; вызывается под mu.
+func (c *Console) render() error {
+	_, err := c.out.WriteString(c.screen.render())
+	return err
+}
+
+func (c *Console) resize() {
+	width, height, err := readline.GetSize(int(c.out.Fd()))
+	if err != nil || width == 0 || height == 0 {
+		// размер неизвестен, например у только что созданного псевдотерминала
+		width, height = 80, 24
+	}
+	c.update(func(s *screen) {
+		s.resize(width, height)
+	})
+}
+
+func (c *Console) readLine(ctx context.Context) (string, error) {
+	// прерывание, пришедшее между чтениями, достаётся следующему чтению
+	select {
+	case <-c.cancel:
+		return "", core.NewAppError(core.ErrInterrupt, "interrupt", nil)
+	default:
+	}
+	c.update(func(s *screen) {
+		s.startInput()
+	})
+	c.loadHistory()
+	browse := len(c.history)
+	for {
+		k, err := c.nextKey(ctx)
+		if err != nil {
+			c.update(func(s *screen) {
+				s.stopInput()
+			})
+			return "", err
+		}
+		switch k.code {
+		case keyEnter:
+			var line string
+			c.update(func(s *screen) {
+				line = s.submitInput()
+			})
+			c.addHistory(line)
+			return strings.TrimSpace(line), nil
+		case keyEOF:
+			empty := false
+			c.update(func(s *screen) {
+				if empty = len(s.input) == 0; empty {
+					s.stopInput()
+				} else {
+					s.editInput(key{code: keyDelete})
+				}
+			})
+			if empty {
+				return "", core.NewAppError(core.ErrEOF, "interrupt", nil)
+			}
+		case keyUp, keyDown:
+			if k.code == keyUp && browse > 0 {
+				browse--
+			} else if k.code == keyDown && browse < len(c.history) {
+				browse++
+			} else {
+				continue
+			}
+			text := ""
+			if browse < len(c.history) {
+				text = c.history[browse]
+			}
+			c.update(func(s *screen) {
+				s.setInput(text)
+			})
+		default:
+			c.update(func(s *screen) {
+				s.editInput(k)
+			})
+		}
+	}
+}
+
+func (c *Console) SetHistory(scope string, lines []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.nextScope, c.nextHistory = scope, lines
+}
+
+// loadHistory подменяет историю, если с прошлого чтения сменилась её область; историю листает только горутина чтения.
+func (c *Console) loadHistory() {
+	c.mu.Lock()
+	scope, lines := c.nextScope, c.nextHistory
+	c.mu.Unlock()
+	if scope == c.historyScope {
+		return
+	}
+	c.history = append([]string(nil), lines...)
+	c.historyScope = scope
+}
+
+func (c *Console) addHistory(line string) {
+	line = strings.TrimSpace(line)
+	if line == "" || c.historyLimit == 0 || (len(c.history) > 0 && c.history[len(c.history)-1] == line) {
+		return
+	}
+	c.history = append(c.history, line)
+	if len(c.history) > c.historyLimit {
+		c.history = c.history[len(c.history)-c.historyLimit:]
+	}
+}
+
+type logWriter struct {
+	console *Console
+}
+
+func (w logWriter) Write(p []byte) (int, error) {
+	if err := w.console.Write(string(p)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
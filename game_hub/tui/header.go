@@ -0,0 +1,50 @@
+package tui
+
+import (
+	"game_hub/core"
+	"slices"
+	"strings"
+)
+
+// Attach подключает консоль к движку: после каждого показа состояния обновляются заголовок и строка команд.
+func (c *Console) Attach(engine *core.Engine) {
+	previous := engine.Hooks.OnDisplay
+	engine.Hooks.OnDisplay = func(e *core.Engine, state core.State) {
+		if previous != nil {
+			previous(e, state)
+		}
+		title, status := header(e.App, e.UI)
+		c.SetHeader(title, status)
+		c.SetCommands(commandList(e.App, e.UI))
+	}
+}
+
+// header возвращает название хаба и запущенной игры, а также строку состояния игры, если она её предоставляет.
+func header(ctx *core.AppContext, ui *core.UiContext) (title, status string) {
+	title = " " + ui.GetLocalizedMsg(ui.AppLocalizer, "hub_title")
+	if ctx.Game == nil {
+		return title, ""
+	}
+	if name := ui.GetOptionalLocalizedMsg(ui.AppLocalizer, ctx.Game.GetId(), "name"); name != "" {
+		title += " › " + name
+	}
+	if withStatus, ok := ctx.Game.(core.GameWithStatus); ok {
+		if status = withStatus.Status(ui); status != "" {
+			status += " "
+		}
+	}
+	return title, status
+}
+
+// commandList перечисляет названия доступных команд от самого приоритетного слоя к общим.
+func commandList(ctx *core.AppContext, ui *core.UiContext) string {
+	names := make([]string, 0)
+	for _, layer := range core.CommandLayers {
+		for _, cmd := range ui.CommandRegistry.GetVisibleCommands(layer, ctx) {
+			if name := ui.GetLocalizedCmdName(cmd); name != "" && !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	return " " + ui.GetLocalizedMsg(ui.AppLocalizer, "status_bar_commands") + ": " + strings.Join(names, ", ")
+}
@@ -0,0 +1,172 @@
+package tui
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"game_hub/core"
+	"github.com/chzyer/readline"
+	"strings"
+	"time"
+)
+
+// escapeDelay — сколько ждать продолжения escape-последовательности, прежде чем считать её нажатием Escape
+const escapeDelay = 50 * time.Millisecond
+
+type keyCode int
+
+const (
+	keyRune keyCode = iota
+	keyEnter
+	keyEscape
+	keyBackspace
+	keyDelete
+	keyEOF
+	keyUp
+	keyDown
+	keyLeft
+	keyRight
+	keyHome
+	keyEnd
+	keyPageUp
+	keyPageDown
+)
+
+type key struct {
+	code keyCode
+	rune rune
+}
+
+var escapeKeys = map[rune]keyCode{
+	'A': keyUp,
+	'B': keyDown,
+	'C': keyRight,
+	'D': keyLeft,
+	'H': keyHome,
+	'F': keyEnd,
+}
+
+// tildeKeys — клавиши, которые приходят последовательностями вида ESC [ 5 ~
+var tildeKeys = map[string]keyCode{
+	"1": keyHome,
+	"7": keyHome,
+	"4": keyEnd,
+	"8": keyEnd,
+	"3": keyDelete,
+	"5": keyPageUp,
+	"6": keyPageDown,
+}
+
+// readInput читает символы терминала в отдельной горутине, пока не закончится ввод.
+func (c *Console) readInput() {
+	reader := bufio.NewReader(c.in)
+	for {
+		r, _, err := reader.ReadRune()
+		if err != nil {
+			close(c.runes)
+			return
+		}
+		c.runes <- r
+	}
+}
+
+// nextRune ждёт символ не дольше timeout; нулевой timeout означает ожидание без ограничения.
+func (c *Console) nextRune(ctx context.Context, timeout time.Duration) (r rune, ok bool, err error) {
+	if len(c.pending) > 0 {
+		r, c.pending = c.pending[0], c.pending[1:]
+		return r, true, nil
+	}
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+	select {
+	case r, ok = <-c.runes:
+		if !ok {
+			return 0, false, core.NewAppError(core.ErrEOF, "interrupt", nil)
+		}
+		return r, true, nil
+	case <-expired:
+		return 0, false, nil
+	case <-c.cancel:
+		return 0, false, core.NewAppError(core.ErrInterrupt, "interrupt", nil)
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return 0, false, core.NewAppError(core.ErrTimeout, "input_timeout", nil)
+		}
+		return 0, false, core.NewAppError(core.ErrInterrupt, "interrupt", nil)
+	}
+}
+
+func (c *Console) nextKey(ctx context.Context) (key, error) {
+	for {
+		r, _, err := c.nextRune(ctx, 0)
+		if err != nil {
+			return key{}, err
+		}
+		switch r {
+		case '\r', '\n':
+			return key{code: keyEnter}, nil
+		case readline.CharInterrupt:
+			return key{}, core.NewAppError(core.ErrInterrupt, "interrupt", nil)
+		case readline.CharDelete:
+			return key{code: keyEOF}, nil
+		case readline.CharBackspace, '\b':
+			return key{code: keyBackspace}, nil
+		case readline.CharLineStart:
+			return key{code: keyHome}, nil
+		case readline.CharLineEnd:
+			return key{code: keyEnd}, nil
+		case readline.CharCtrlU:
+			return key{code: keyEscape}, nil
+		case readline.CharEsc:
+			k, ok, err := c.escapeSequence(ctx)
+			if err != nil || ok {
+				return k, err
+			}
+			// неизвестные последовательности пропускаем
+			continue
+		}
+		if r >= ' ' {
+			return key{code: keyRune, rune: r}, nil
+		}
+	}
+}
+
+// escapeSequence разбирает то, что пришло после Escape: ESC [ A, ESC O A, ESC [ 5 ~ и подобные последовательности
+// клавиш управления, а одиночный Escape узнаётся по тому, что за ним ничего не последовало.
+func (c *Console) escapeSequence(ctx context.Context) (key, bool, error) {
+	r, ok, err := c.nextRune(ctx, escapeDelay)
+	if err != nil {
+		return key{}, false, err
+	}
+	if !ok {
+		return key{code: keyEscape}, true, nil
+	}
+	if r != '[' && r != 'O' {
+		// Alt с клавишей считаем нажатием Escape, а сам символ оставляем для следующего чтения
+		c.pending = append(c.pending, r)
+		return key{code: keyEscape}, true, nil
+	}
+	var params strings.Builder
+	for {
+		r, ok, err = c.nextRune(ctx, escapeDelay)
+		if err != nil || !ok {
+			return key{}, false, err
+		}
+		// параметры последовательности — цифры и ';', клавишу определяет завершающий символ
+		if r >= 0x40 && r <= 0x7e {
+			break
+		}
+		params.WriteRune(r)
+	}
+	if r == '~' {
+		number, _, _ := strings.Cut(params.String(), ";")
+		code, exists := tildeKeys[number]
+		return key{code: code}, exists, nil
+	}
+	code, exists := escapeKeys[r]
+	return key{code: code}, exists, nil
+}
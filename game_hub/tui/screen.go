@@ -0,0 +1,245 @@
+package tui
+
+import (
+	"fmt"
+	"game_hub/utils"
+	"strings"
+)
+
+const (
+	hideCursor = "\x1b[?25l"
+	showCursor = "\x1b[?25h"
+	clearLine  = "\x1b[K"
+	reverse    = "\x1b[7m"
+	resetStyle = "\x1b[0m"
+)
+
+const prompt = "> "
+
+// maxLogLines ограничивает журнал, чтобы долгая сессия не занимала всё больше памяти
+const maxLogLines = 1000
+
+// screen хранит всё, что изображено на экране, и строит его изображение целиком.
+// Сверху вниз: заголовок, журнал, строка команд и строка ввода.
+type screen struct {
+	width    int
+	height   int
+	title    string
+	status   string
+	commands string
+	log      []string
+	// partial — последняя строка журнала, которая ещё не закончилась переводом строки
+	partial string
+	// scroll — на сколько строк журнал прокручен вверх от последней
+	scroll  int
+	editing bool
+	input   []rune
+	cursor  int
+}
+
+func (s *screen) resize(width, height int) {
+	s.width, s.height = max(width, 1), max(height, 4)
+	s.scrollBy(0)
+}
+
+// logHeight — число строк под журнал между заголовком и строкой команд
+func (s *screen) logHeight() int {
+	return max(s.height-3, 1)
+}
+
+// write добавляет текст в журнал; новые сообщения прокручивают журнал к концу.
+func (s *screen) write(text string) {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "")
+	text = strings.ReplaceAll(text, "\t", "    ")
+	lines := strings.Split(text, "\n")
+	s.partial += lines[0]
+	for _, line := range lines[1:] {
+		s.log = append(s.log, s.partial)
+		s.partial = line
+	}
+	if len(s.log) > maxLogLines {
+		s.log = s.log[len(s.log)-maxLogLines:]
+	}
+	s.scroll = 0
+}
+
+// wrappedLog разбивает журнал на строки экрана текущей ширины.
+func (s *screen) wrappedLog() []string {
+	lines := make([]string, 0, len(s.log)+1)
+	for _, line := range s.log {
+		lines = append(lines, wrapLine(line, s.width)...)
+	}
+	if s.partial != "" {
+		lines = append(lines, wrapLine(s.partial, s.width)...)
+	}
+	return carryStyles(lines)
+}
+
+// carryStyles переносит оформление на следующие строки: каждая строка экрана рисуется отдельно,
+// поэтому стиль, открытый на одной строке и закрытый на другой, повторяется в начале каждой из них.
+func carryStyles(lines []string) []string {
+	active := ""
+	for i, line := range lines {
+		if active == "" && !strings.Contains(line, "\x1b[") {
+			continue
+		}
+		lines[i] = active + line + resetStyle
+		for rest := line; ; {
+			start := strings.Index(rest, "\x1b[")
+			if start < 0 {
+				break
+			}
+			end := strings.IndexByte(rest[start:], 'm')
+			if end < 0 {
+				break
+			}
+			if sequence := rest[start : start+end+1]; sequence == resetStyle {
+				active = ""
+			} else {
+				active += sequence
+			}
+			rest = rest[start+end+1:]
+		}
+	}
+	return lines
+}
+
+func (s *screen) scrollBy(lines int) {
+	maxScroll := max(len(s.wrappedLog())-s.logHeight(), 0)
+	s.scroll = min(max(s.scroll+lines, 0), maxScroll)
+}
+
+func (s *screen) startInput() {
+	s.editing = true
+	s.setInput("")
+}
+
+func (s *screen) stopInput() {
+	s.editing = false
+	s.setInput("")
+}
+
+func (s *screen) setInput(text string) {
+	s.input = []rune(text)
+	s.cursor = len(s.input)
+}
+
+// submitInput завершает ввод и повторяет введённую строку в журнале.
+func (s *screen) submitInput() string {
+	line := string(s.input)
+	echo := prompt + line
+	if s.partial != "" {
+		// строка ввода продолжает незаконченный вопрос
+		echo = line
+	}
+	s.write(echo + "\n")
+	s.stopInput()
+	return line
+}
+
+// editInput применяет к строке ввода клавиши редактирования и прокрутки журнала.
+func (s *screen) editInput(k key) {
+	switch k.code {
+	case keyRune:
+		s.input = append(s.input[:s.cursor], append([]rune{k.rune}, s.input[s.cursor:]...)...)
+		s.cursor++
+	case keyBackspace:
+		if s.cursor > 0 {
+			s.input = append(s.input[:s.cursor-1], s.input[s.cursor:]...)
+			s.cursor--
+		}
+	case keyDelete:
+		if s.cursor < len(s.input) {
+			s.input = append(s.input[:s.cursor], s.input[s.cursor+1:]...)
+		}
+	case keyLeft:
+		s.cursor = max(s.cursor-1, 0)
+	case keyRight:
+		s.cursor = min(s.cursor+1, len(s.input))
+	case keyHome:
+		s.cursor = 0
+	case keyEnd:
+		s.cursor = len(s.input)
+	case keyEscape:
+		s.setInput("")
+	case keyPageUp:
+		s.scrollBy(s.logHeight() - 1)
+	case keyPageDown:
+		s.scrollBy(-(s.logHeight() - 1))
+	}
+}
+
+func (s *screen) render() string {
+	var b strings.Builder
+	b.WriteString(hideCursor)
+	moveTo(&b, 1, 1)
+	b.WriteString(bar(s.title, s.status, s.width))
+	lines := s.wrappedLog()
+	end := len(lines) - s.scroll
+	start := max(end-s.logHeight(), 0)
+	for row := 0; row < s.logHeight(); row++ {
+		moveTo(&b, row+2, 1)
+		if start+row < end {
+			b.WriteString(lines[start+row])
+		}
+		b.WriteString(clearLine)
+	}
+	position := ""
+	if s.scroll > 0 {
+		position = fmt.Sprintf("↑%d", s.scroll)
+	}
+	moveTo(&b, s.height-1, 1)
+	b.WriteString(bar(s.commands, position, s.width))
+	moveTo(&b, s.height, 1)
+	if !s.editing {
+		b.WriteString(clearLine)
+		return b.String()
+	}
+	// длинная строка ввода сдвигается так, чтобы курсор оставался на экране
+	available := max(s.width-len(prompt)-1, 1)
+	offset := 0
+	for utils.DisplayWidth(string(s.input[offset:s.cursor])) > available {
+		offset++
+	}
+	visible := truncate(string(s.input[offset:]), available+1)
+	b.WriteString(prompt + visible + clearLine)
+	moveTo(&b, s.height, len(prompt)+utils.DisplayWidth(string(s.input[offset:s.cursor]))+1)
+	b.WriteString(showCursor)
+	return b.String()
+}
+
+func moveTo(b *strings.Builder, row, column int) {
+	fmt.Fprintf(b, "\x1b[%d;%dH", row, column)
+}
+
+// bar строит строку в инверсных цветах: left прижат влево, right — вправо; не поместившееся обрезается.
+func bar(left, right string, width int) string {
+	right = truncate(right, width)
+	left = truncate(left, max(width-utils.DisplayWidth(right)-1, 0))
+	gap := max(width-utils.DisplayWidth(left)-utils.DisplayWidth(right), 0)
+	return reverse + left + strings.Repeat(" ", gap) + right + resetStyle
+}
+
+func truncate(text string, width int) string {
+	if utils.DisplayWidth(text) <= width {
+		return text
+	}
+	if width < 1 {
+		return ""
+	}
+	used := 0
+	for i, r := range text {
+		if used+utils.RuneWidth(r) > width-1 {
+			return text[:i] + "…"
+		}
+		used += utils.RuneWidth(r)
+	}
+	return text
+}
+
+// wrapLine переносит строку журнала по ширине экрана; журнал хранит строки без переносов,
+// поэтому после изменения размера окна текст переносится заново.
+func wrapLine(line string, width int) []string {
+	return strings.Split(utils.WrapText(line, width), "\r\n")
+}
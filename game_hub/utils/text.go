@@ -0,0 +1,144 @@
+package utils
+
+import (
+	"golang.org/x/text/width"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// listMarker — начало пункта списка: "1. ", "2) ", "- ", "* " или "• "
+var listMarker = regexp.MustCompile(`^(\d+[.)]|[-*•])\s+`)
+
+// RuneWidth возвращает число колонок терминала, которые занимает символ: 2 для широких символов
+// восточноазиатских письменностей, 0 для управляющих символов и комбинируемых знаков, 1 для остальных.
+func RuneWidth(r rune) int {
+	if r < ' ' || (r >= 0x7f && r < 0xa0) || unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf) {
+		return 0
+	}
+	switch width.LookupRune(r).Kind() {
+	case width.EastAsianWide, width.EastAsianFullwidth:
+		return 2
+	}
+	return 1
+}
+
+// DisplayWidth возвращает ширину строки в колонках терминала; escape-последовательности оформления ширины не имеют.
+func DisplayWidth(s string) int {
+	total := 0
+	for len(s) > 0 {
+		if n := escapeLength(s); n > 0 {
+			s = s[n:]
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s)
+		total += RuneWidth(r)
+		s = s[size:]
+	}
+	return total
+}
+
+// escapeLength возвращает длину CSI-последовательности вида ESC [ ... m в начале s или 0, если её там нет.
+func escapeLength(s string) int {
+	if !strings.HasPrefix(s, "\x1b[") {
+		return 0
+	}
+	for i := 2; i < len(s); i++ {
+		if s[i] >= 0x40 && s[i] <= 0x7e {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// WrapText переносит строки текста по словам так, чтобы они занимали не больше width колонок.
+// Отступ в начале строки сохраняется, продолжение пункта списка выравнивается по его тексту,
+// а слова шире строки разрезаются. Перенос вставляется как "\r\n"; при width <= 0 текст не меняется.
+func WrapText(input string, width int) string {
+	if width <= 0 {
+		return input
+	}
+	var result strings.Builder
+	for _, line := range strings.SplitAfter(input, "\n") {
+		body := strings.TrimRight(line, "\r\n")
+		wrapLine(&result, body, width)
+		result.WriteString(line[len(body):])
+	}
+	return result.String()
+}
+
+func wrapLine(b *strings.Builder, line string, width int) {
+	if DisplayWidth(line) <= width {
+		b.WriteString(line)
+		return
+	}
+	text := strings.TrimLeft(line, " \t")
+	indent := line[:len(line)-len(text)]
+	hanging := indent
+	if marker := listMarker.FindString(text); marker != "" {
+		hanging += strings.Repeat(" ", DisplayWidth(marker))
+	}
+	if DisplayWidth(hanging) >= width/2 {
+		// слишком большой отступ оставил бы для текста слишком узкую колонку
+		hanging = ""
+	}
+	b.WriteString(indent)
+	used := DisplayWidth(indent)
+	// started — есть ли на текущей строке слова; пробелы перед первым словом строки не выводятся
+	started := false
+	for text != "" {
+		word := strings.TrimLeft(text, " \t")
+		space := text[:len(text)-len(word)]
+		end := strings.IndexAny(word, " \t")
+		if end < 0 {
+			end = len(word)
+		}
+		word, text = word[:end], word[end:]
+		if word == "" {
+			break
+		}
+		if started && used+DisplayWidth(space)+DisplayWidth(word) > width {
+			b.WriteString("\r\n" + hanging)
+			used, started = DisplayWidth(hanging), false
+		}
+		if started {
+			b.WriteString(space)
+			used += DisplayWidth(space)
+		}
+		// слово шире строки разрезается; сюда доходит только слово в начале строки
+		for used+DisplayWidth(word) > width {
+			head, tail := cutAtWidth(word, width-used)
+			if head == "" {
+				// символ шире всей строки всё равно выводится, иначе перенос не закончится
+				_, size := utf8.DecodeRuneInString(word)
+				head, tail = word[:size], word[size:]
+			}
+			b.WriteString(head + "\r\n" + hanging)
+			used, word = DisplayWidth(hanging), tail
+		}
+		b.WriteString(word)
+		used += DisplayWidth(word)
+		started = true
+	}
+}
+
+// cutAtWidth делит слово так, чтобы первая часть занимала не больше width колонок;
+// комбинируемые знаки остаются с символом, к которому относятся, а escape-последовательности не разрезаются.
+func cutAtWidth(word string, width int) (head, tail string) {
+	used := 0
+	for i := 0; i < len(word); {
+		if n := escapeLength(word[i:]); n > 0 {
+			i += n
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(word[i:])
+		w := RuneWidth(r)
+		if used+w > width {
+			return word[:i], word[i:]
+		}
+		used += w
+		i += size
+	}
+	return word, ""
+}